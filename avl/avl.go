@@ -1,5 +1,7 @@
 package avl
 
+import "strings"
+
 // 这个深度，已足以保存最少4万亿个数据，最多115亿亿个数据。
 // 列出一个深度和最小装载数、最大装载数的表：
 // 19 => 1,0946					=>	5.242e05
@@ -20,10 +22,10 @@ type Key struct {
 	S typeB
 }
 
-// 二叉树的指针
+// 节点中的键。映射的节点是entry的一部分，entry指向所在的entry；集合的节点没有值，entry为nil
 type item struct {
 	Key
-	Val typeC
+	*entry
 }
 
 // AVL树的节点
@@ -37,17 +39,27 @@ type Node struct {
 
 // 插入/删除时查询的缓存记录
 type trace struct {
-	st [depth]**Node
-	sp int
+	st  [depth]**Node
+	sp  int
+	rot *rotation
+	fix func(*Node) // 增强信息的维护函数，为nil表示没有增强信息
+}
+
+// 映射中保存键值对的单元，平衡代码只使用其中的Node，因此集合可以使用只有键的节点
+type entry struct {
+	Node
+	Val typeC
 }
 
 // AVL树
 type AVL struct {
 	root *Node
+	rot  rotation
+	mod  uint64 // 插入或删除节点的次数，用于发现迭代期间的修改
 }
 
 // 简化代码用的，用来代替空节点的节点
-var null = &Node{}
+var null = new(entry).bind()
 
 // 方便计算深度
 func max(x, y int8) int8 {
@@ -75,7 +87,7 @@ func compare(x, y *Key) int8 {
 	return 0
 }
 
-// 获得前驱节点，用于简单迭代
+// 获得前驱节点，用于简单迭代；不会检查迭代期间的修改（见ErrModified）
 func (this *Node) Prev() *Node {
 	p := this.ptA
 	if this.mrk&2 != 0 {
@@ -85,7 +97,7 @@ func (this *Node) Prev() *Node {
 	return p
 }
 
-// 获得后继节点，用于简单迭代
+// 获得后继节点，用于简单迭代；不会检查迭代期间的修改（见ErrModified）
 func (this *Node) Next() *Node {
 	p := this.ptB
 	if this.mrk&1 != 0 {
@@ -118,16 +130,52 @@ func (this *Node) Key() (typeA, typeB) {
 	return this.item.Key.N, this.item.Key.S
 }
 
-// 获得节点的值，采用函数避免误修改
+// 获得节点的值，采用函数避免误修改；集合的节点没有值，返回nil
 func (this *Node) Val() typeC {
-	return this.item.Val
+	if this.entry == nil {
+		return nil
+	}
+	return this.entry.Val
 }
 
-// 设置节点的值
+// 设置节点的值，不能用于集合的节点
 func (this *Node) Set(v typeC) {
 	this.item.Val = v
 }
 
+// 使键值对的节点指向其自身，返回该节点
+func (this *entry) bind() *Node {
+	this.item.entry = this
+	return &this.Node
+}
+
+// 创建保存键值对的新节点
+func newEntry(k *Key, v typeC) *Node {
+	return (&entry{Node{0, 1, nil, nil, item{Key: *k}}, v}).bind()
+}
+
+// 复制节点，新节点与原节点的键、值和子节点指针都相同
+func (this *Node) copy() *Node {
+	if this.entry == nil {
+		p := new(Node)
+		*p = *this
+		return p
+	}
+	e := new(entry)
+	*e = *this.entry
+	return e.bind()
+}
+
+// 一次性分配n个保存键值对的节点
+func newEntries(n int) []*Node {
+	entries := make([]entry, n)
+	list := make([]*Node, n)
+	for i := range entries {
+		list[i] = entries[i].bind()
+	}
+	return list
+}
+
 // 用来以文本格式显示二叉树
 func (this *Node) Show(f func(*Node) string) (int, []string) {
 	var (
@@ -174,81 +222,109 @@ func (this *Node) Show(f func(*Node) string) (int, []string) {
 	return len(x), append(append(x, v...), y...)
 }
 
-// 从枝叶到根节点维护AVL树
-func (this *trace) Maintain() {
-	for i := this.sp - 1; i >= 0; i-- {
-		p := *this.st[i]
-		s := p.hgt
-		l, r := p.Lson(), p.Rson()
-		switch t := l.hgt - r.hgt; {
-		case t > +1:
-			b, d := l.Lson(), l.Rson()
-			if b.hgt >= d.hgt {
-				*this.st[i] = l
-				l.ptB, l.mrk = p, l.mrk|1
-				if d == null {
-					p.ptA, p.mrk = l, p.mrk&1
-				} else {
-					p.ptA, p.mrk = d, p.mrk|2
-				}
-				p.hgt = max(d.hgt, r.hgt) + 1
-				l.hgt = max(b.hgt, p.hgt) + 1
-				p = l
+// 对节点进行必要的旋转以恢复平衡并更新高度，返回旋转后占据该节点原位置的节点，c累计旋转次数。
+// f不为nil时，按自底向上的顺序对各个高度被更新的节点调用f以维护增强信息
+func balance(p *Node, c *rotation, f func(*Node)) *Node {
+	l, r := p.Lson(), p.Rson()
+	switch t := l.hgt - r.hgt; {
+	case t > +1:
+		b, d := l.Lson(), l.Rson()
+		if b.hgt >= d.hgt {
+			l.ptB, l.mrk = p, l.mrk|1
+			if d == null {
+				p.ptA, p.mrk = l, p.mrk&1
 			} else {
-				x, y := d.Lson(), d.Rson()
-				*this.st[i] = d
-				d.ptA, d.ptB, d.mrk = l, p, 3
-				if x == null {
-					l.ptB, l.mrk = d, l.mrk&2
-				} else {
-					l.ptB, l.mrk = x, l.mrk|1
-				}
-				if y == null {
-					p.ptA, p.mrk = d, p.mrk&1
-				} else {
-					p.ptA, p.mrk = y, p.mrk|2
-				}
-				l.hgt = max(b.hgt, x.hgt) + 1
-				p.hgt = max(y.hgt, r.hgt) + 1
-				d.hgt = max(l.hgt, p.hgt) + 1
-				p = d
+				p.ptA, p.mrk = d, p.mrk|2
 			}
-		case t < -1:
-			b, d := r.Lson(), r.Rson()
-			if b.hgt <= d.hgt {
-				*this.st[i] = r
-				r.ptA, r.mrk = p, r.mrk|2
-				if b == null {
-					p.ptB, p.mrk = r, p.mrk&2
-				} else {
-					p.ptB, p.mrk = b, p.mrk|1
-				}
-				p.hgt = max(l.hgt, b.hgt) + 1
-				r.hgt = max(p.hgt, d.hgt) + 1
-				p = r
+			p.hgt = max(d.hgt, r.hgt) + 1
+			l.hgt = max(b.hgt, p.hgt) + 1
+			if f != nil {
+				f(p)
+				f(l)
+			}
+			c.single++
+			return l
+		} else {
+			x, y := d.Lson(), d.Rson()
+			d.ptA, d.ptB, d.mrk = l, p, 3
+			if x == null {
+				l.ptB, l.mrk = d, l.mrk&2
 			} else {
-				x, y := b.Lson(), b.Rson()
-				*this.st[i] = b
-				b.ptA, b.ptB, b.mrk = p, r, 3
-				if x == null {
-					p.ptB, p.mrk = b, p.mrk&2
-				} else {
-					p.ptB, p.mrk = x, p.mrk|1
-				}
-				if y == null {
-					r.ptA, r.mrk = b, r.mrk&1
-				} else {
-					r.ptA, r.mrk = y, r.mrk|2
-				}
-				p.hgt = max(l.hgt, x.hgt) + 1
-				r.hgt = max(y.hgt, d.hgt) + 1
-				b.hgt = max(p.hgt, r.hgt) + 1
-				p = b
+				l.ptB, l.mrk = x, l.mrk|1
 			}
-		default:
-			p.hgt = max(l.hgt, r.hgt) + 1
+			if y == null {
+				p.ptA, p.mrk = d, p.mrk&1
+			} else {
+				p.ptA, p.mrk = y, p.mrk|2
+			}
+			l.hgt = max(b.hgt, x.hgt) + 1
+			p.hgt = max(y.hgt, r.hgt) + 1
+			d.hgt = max(l.hgt, p.hgt) + 1
+			if f != nil {
+				f(l)
+				f(p)
+				f(d)
+			}
+			c.double++
+			return d
+		}
+	case t < -1:
+		b, d := r.Lson(), r.Rson()
+		if b.hgt <= d.hgt {
+			r.ptA, r.mrk = p, r.mrk|2
+			if b == null {
+				p.ptB, p.mrk = r, p.mrk&2
+			} else {
+				p.ptB, p.mrk = b, p.mrk|1
+			}
+			p.hgt = max(l.hgt, b.hgt) + 1
+			r.hgt = max(p.hgt, d.hgt) + 1
+			if f != nil {
+				f(p)
+				f(r)
+			}
+			c.single++
+			return r
+		} else {
+			x, y := b.Lson(), b.Rson()
+			b.ptA, b.ptB, b.mrk = p, r, 3
+			if x == null {
+				p.ptB, p.mrk = b, p.mrk&2
+			} else {
+				p.ptB, p.mrk = x, p.mrk|1
+			}
+			if y == null {
+				r.ptA, r.mrk = b, r.mrk&1
+			} else {
+				r.ptA, r.mrk = y, r.mrk|2
+			}
+			p.hgt = max(l.hgt, x.hgt) + 1
+			r.hgt = max(y.hgt, d.hgt) + 1
+			b.hgt = max(p.hgt, r.hgt) + 1
+			if f != nil {
+				f(p)
+				f(r)
+				f(b)
+			}
+			c.double++
+			return b
 		}
-		if s == p.hgt {
+	}
+	p.hgt = max(l.hgt, r.hgt) + 1
+	if f != nil {
+		f(p)
+	}
+	return p
+}
+
+// 从枝叶到根节点维护AVL树，有增强信息时需要一直维护到根节点
+func (this *trace) Maintain() {
+	for i := this.sp - 1; i >= 0; i-- {
+		p := *this.st[i]
+		s := p.hgt
+		p = balance(p, this.rot, this.fix)
+		*this.st[i] = p
+		if s == p.hgt && this.fix == nil {
 			break
 		}
 	}
@@ -267,6 +343,7 @@ loop:
 			*this.st[i-1] = l
 			this.st[i] = &l.ptB
 			l.hgt = 0 // 目的在于maintain时，通知maintain函数该节点是变化过的节点
+			this.rot.single++
 			l.ptB, l.mrk = p, l.mrk|1
 			if t == null {
 				p.ptA, p.mrk = l, p.mrk&1
@@ -281,6 +358,7 @@ loop:
 			*this.st[i-1] = r
 			this.st[i] = &r.ptA
 			r.hgt = 0 // 目的在于maintain时，通知maintain函数该节点是变化过的节点
+			this.rot.single++
 			r.ptA, r.mrk = p, r.mrk|2
 			if t == null {
 				p.ptB, p.mrk = r, p.mrk&2
@@ -317,16 +395,28 @@ func (this *trace) Search(x **Node, k *Key) bool {
 	return false
 }
 
-// 加入键值对，如果已有键则更新值。
-func (this *trace) Update(x **Node, k *Key, v typeC) {
+// 加入键值对，如果已有键则更新值。返回是否加入了新的节点
+func (this *trace) Update(x **Node, k *Key, v typeC) bool {
 	ok := this.Search(x, k)
 	if ok {
 		(*this.st[this.sp-1]).item.Val = v
-		return
+		if this.fix != nil {
+			this.Maintain()
+		}
+		return false
 	}
-	p := new(Node)
-	*p = Node{0, 1, nil, nil, item{*k, v}}
-	x = this.st[this.sp-1]
+	this.Attach(k, v)
+	return true
+}
+
+// 在查找路径最后记录的空位上加入新的键值对并维护AVL树
+func (this *trace) Attach(k *Key, v typeC) {
+	this.attach(newEntry(k, v))
+}
+
+// 在查找路径最后记录的空位上加入新节点p并维护AVL树，p的高度须为1且没有子节点
+func (this *trace) attach(p *Node) {
+	x := this.st[this.sp-1]
 	t := *x
 	*x = p
 	this.sp--
@@ -367,30 +457,20 @@ func (this *trace) Insert(x **Node, k *Key, v typeC) {
 		}
 		this.sp = i
 	}
-	p := new(Node)
-	*p = Node{0, 1, nil, nil, item{*k, v}}
-	x = this.st[this.sp-1]
-	t := *x
-	*x = p
-	this.sp--
-	if this.sp > 0 {
-		q := *this.st[this.sp-1]
-		if &q.ptB == x {
-			q.mrk |= 1
-			p.ptA, p.ptB = q, t
-		} else {
-			q.mrk |= 2
-			p.ptA, p.ptB = t, q
-		}
-	}
-	this.Maintain()
+	this.Attach(k, v)
 }
 
-// 删除键值对
-func (this *trace) Delete(x **Node, k *Key) {
+// 删除键值对，返回键是否存在
+func (this *trace) Delete(x **Node, k *Key) bool {
 	if !this.Search(x, k) {
-		return
+		return false
 	}
+	this.Remove()
+	return true
+}
+
+// 删除查找路径最后记录的节点
+func (this *trace) Remove() {
 	this.ToLeaf()
 	p := *this.st[this.sp-1]
 	this.sp--
@@ -417,20 +497,38 @@ func New() *AVL {
 
 // 如果键已存在，更新值；如果不存在，插入新的键值对
 func (this *AVL) Update(n typeA, s typeB, v typeC) {
-	var tr trace
-	tr.Update(&this.root, &Key{n, s}, v)
+	if debug {
+		defer this.check()
+	}
+	tr := trace{rot: &this.rot}
+	if tr.Update(&this.root, &Key{n, s}, v) {
+		this.mod++
+	}
 }
 
 // 不管键已存在或不存在，都插入新的键值对
 func (this *AVL) Insert(n typeA, s typeB, v typeC) {
-	var tr trace
+	if debug {
+		defer this.check()
+	}
+	tr := trace{rot: &this.rot}
 	tr.Insert(&this.root, &Key{n, s}, v)
+	this.mod++
 }
 
-// 根据键删除键值对所对应的节点
-func (this *AVL) Delete(n typeA, s typeB) {
-	var tr trace
-	tr.Delete(&this.root, &Key{n, s})
+// 根据键删除键值对所对应的节点，返回被删除的值以及键是否存在
+func (this *AVL) Delete(n typeA, s typeB) (typeC, bool) {
+	if debug {
+		defer this.check()
+	}
+	tr := trace{rot: &this.rot}
+	if !tr.Search(&this.root, &Key{n, s}) {
+		return nil, false
+	}
+	v := (*tr.st[tr.sp-1]).Val()
+	tr.Remove()
+	this.mod++
+	return v, true
 }
 
 // 根据键查找键值对所对应的节点
@@ -489,7 +587,7 @@ func (this *AVL) Show(f func(*Node) string, spin bool) string {
 			str[i] = "    " + line
 		}
 	}
-	block := ""
+	var block strings.Builder
 	x, y := len(str), 0
 	if spin {
 		for i := 0; i < x; i++ {
@@ -519,12 +617,14 @@ func (this *AVL) Show(f func(*Node) string, spin bool) string {
 			}
 		}
 		for i := 0; i < y; i++ {
-			block += string(out[i]) + "\r\n"
+			block.Write(out[i])
+			block.WriteString("\r\n")
 		}
 	} else {
 		for i := 0; i < x; i++ {
-			block += str[i] + "\r\n"
+			block.WriteString(str[i])
+			block.WriteString("\r\n")
 		}
 	}
-	return block
+	return block.String()
 }
\ No newline at end of file
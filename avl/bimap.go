@@ -0,0 +1,135 @@
+package avl
+
+import "fmt"
+
+// 双向映射，键和值都是Key且各自唯一。由两棵AVL树组成：fwd以键为键、值为值，
+// bwd以值为键、键为值，两个方向都可以查找和按顺序遍历
+type BiMap struct {
+	fwd  AVL
+	bwd  AVL
+	size int
+}
+
+// 创建一个双向映射
+func NewBiMap() *BiMap {
+	p := new(BiMap)
+	p.fwd.root = null
+	p.bwd.root = null
+	return p
+}
+
+// 在树中查找键k，返回对应的Key
+func lookup(t *AVL, k Key) (Key, bool) {
+	if p := t.Search(k.N, k.S); p != nil {
+		return p.item.Val.(Key), true
+	}
+	return Key{}, false
+}
+
+// 建立k与v的对应，k原有的值被替换。v已对应k以外的键时返回错误，映射不变
+func (this *BiMap) Put(k, v Key) error {
+	if o, ok := lookup(&this.bwd, v); ok && o != k {
+		return fmt.Errorf("avl: 值(%d, %s)已对应键(%d, %s)", v.N, v.S, o.N, o.S)
+	}
+	this.ForcePut(k, v)
+	return nil
+}
+
+// 建立k与v的对应，k原有的值以及v原有的键都会被移除
+func (this *BiMap) ForcePut(k, v Key) {
+	this.DeleteByKey(k)
+	this.DeleteByValue(v)
+	this.fwd.Update(k.N, k.S, v)
+	this.bwd.Update(v.N, v.S, k)
+	this.size++
+}
+
+// 返回键k对应的值
+func (this *BiMap) GetByKey(k Key) (Key, bool) {
+	return lookup(&this.fwd, k)
+}
+
+// 返回值v对应的键
+func (this *BiMap) GetByValue(v Key) (Key, bool) {
+	return lookup(&this.bwd, v)
+}
+
+// 删除键k及其对应的值，返回被删除的值以及k是否存在
+func (this *BiMap) DeleteByKey(k Key) (Key, bool) {
+	x, ok := this.fwd.Delete(k.N, k.S)
+	if !ok {
+		return Key{}, false
+	}
+	v := x.(Key)
+	this.bwd.Delete(v.N, v.S)
+	this.size--
+	return v, true
+}
+
+// 删除值v及其对应的键，返回被删除的键以及v是否存在
+func (this *BiMap) DeleteByValue(v Key) (Key, bool) {
+	x, ok := this.bwd.Delete(v.N, v.S)
+	if !ok {
+		return Key{}, false
+	}
+	k := x.(Key)
+	this.fwd.Delete(k.N, k.S)
+	this.size--
+	return k, true
+}
+
+// 返回对应关系的数目
+func (this *BiMap) Len() int {
+	return this.size
+}
+
+// 按t的键的升序对[lo, hi)范围内的每个节点调用f，f返回false时停止，其间修改t会引发panic
+func scan(t *AVL, lo, hi Key, f func(*Node) bool) {
+	f = t.guard(f)
+	for p := t.ceil(&lo); p != nil && compare(&p.item.Key, &hi) < 0; p = p.Next() {
+		if !f(p) {
+			return
+		}
+	}
+}
+
+// 按键的升序对键在[lo, hi)范围内的每一对键值调用f，f返回false时停止，其间修改本映射会引发panic
+func (this *BiMap) RangeByKey(lo, hi Key, f func(k, v Key) bool) {
+	scan(&this.fwd, lo, hi, func(p *Node) bool {
+		return f(p.item.Key, p.item.Val.(Key))
+	})
+}
+
+// 按值的升序对值在[lo, hi)范围内的每一对键值调用f，f返回false时停止，其间修改本映射会引发panic
+func (this *BiMap) RangeByValue(lo, hi Key, f func(k, v Key) bool) {
+	scan(&this.bwd, lo, hi, func(p *Node) bool {
+		return f(p.item.Val.(Key), p.item.Key)
+	})
+}
+
+// 检查双向映射的不变式：两棵树各自的不变式，以及两个方向的对应关系互逆。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *BiMap) Verify() error {
+	if err := this.fwd.Verify(); err != nil {
+		return err
+	}
+	if err := this.bwd.Verify(); err != nil {
+		return err
+	}
+	n := 0
+	for p := this.fwd.Min(); p != nil; p = p.Next() {
+		k, v := p.item.Key, p.item.Val.(Key)
+		if o, ok := lookup(&this.bwd, v); !ok || o != k {
+			return fmt.Errorf("avl: 键(%d, %s)对应值(%d, %s)，反向的对应关系不一致", k.N, k.S, v.N, v.S)
+		}
+		n++
+	}
+	m := 0
+	for p := this.bwd.Min(); p != nil; p = p.Next() {
+		m++
+	}
+	if n != this.size || m != this.size {
+		return fmt.Errorf("avl: 记录的数目为%d，正向有%d个，反向有%d个", this.size, n, m)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 以普通的映射作为模型检查BiMap，两个方向的查找、删除和范围遍历都与模型比较
+func TestBiMap(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	m := NewBiMap()
+	ref := map[Key]Key{}
+	key := func() Key {
+		return Key{rd.Int63n(20), string(rune('a' + rd.Intn(2)))}
+	}
+	// 模型中值为v的键
+	owner := func(v Key) (Key, bool) {
+		for k, x := range ref {
+			if x == v {
+				return k, true
+			}
+		}
+		return Key{}, false
+	}
+	for it := 0; it < 5000; it++ {
+		k, v := key(), key()
+		switch rd.Intn(5) {
+		case 0:
+			o, taken := owner(v)
+			err := m.Put(k, v)
+			if conflict := taken && o != k; conflict != (err != nil) {
+				t.Fatalf("Put(%v, %v) = %v; the value belongs to %v", k, v, err, o)
+			} else if !conflict {
+				ref[k] = v
+			}
+		case 1:
+			m.ForcePut(k, v)
+			if o, ok := owner(v); ok {
+				delete(ref, o)
+			}
+			ref[k] = v
+		case 2:
+			x, ok := m.DeleteByKey(k)
+			if y, found := ref[k]; ok != found || x != y {
+				t.Fatalf("DeleteByKey(%v) = %v, %v; want %v, %v", k, x, ok, y, found)
+			}
+			delete(ref, k)
+		case 3:
+			x, ok := m.DeleteByValue(v)
+			y, found := owner(v)
+			if ok != found || x != y {
+				t.Fatalf("DeleteByValue(%v) = %v, %v; want %v, %v", v, x, ok, y, found)
+			} else if found {
+				delete(ref, y)
+			}
+		case 4:
+			x, ok := m.GetByKey(k)
+			if y, found := ref[k]; ok != found || x != y {
+				t.Fatalf("GetByKey(%v) = %v, %v; want %v, %v", k, x, ok, y, found)
+			}
+			x, ok = m.GetByValue(v)
+			if y, found := owner(v); ok != found || x != y {
+				t.Fatalf("GetByValue(%v) = %v, %v; want %v, %v", v, x, ok, y, found)
+			}
+		}
+		if err := m.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if m.Len() != len(ref) {
+			t.Fatalf("Len() = %d; want %d", m.Len(), len(ref))
+		}
+		// 两个方向的范围遍历分别按键、按值的升序给出模型中落在范围内的对
+		lo, hi := key(), key()
+		var byKey, byVal [][2]Key
+		for x, y := range ref {
+			if compare(&x, &lo) >= 0 && compare(&x, &hi) < 0 {
+				byKey = append(byKey, [2]Key{x, y})
+			}
+			if compare(&y, &lo) >= 0 && compare(&y, &hi) < 0 {
+				byVal = append(byVal, [2]Key{x, y})
+			}
+		}
+		sort.Slice(byKey, func(i, j int) bool { return compare(&byKey[i][0], &byKey[j][0]) < 0 })
+		sort.Slice(byVal, func(i, j int) bool { return compare(&byVal[i][1], &byVal[j][1]) < 0 })
+		var got [][2]Key
+		m.RangeByKey(lo, hi, func(x, y Key) bool {
+			got = append(got, [2]Key{x, y})
+			return true
+		})
+		if len(got) != len(byKey) || len(got) > 0 && !reflect.DeepEqual(got, byKey) {
+			t.Fatalf("RangeByKey(%v, %v) = %v; want %v", lo, hi, got, byKey)
+		}
+		got = got[:0]
+		m.RangeByValue(lo, hi, func(x, y Key) bool {
+			got = append(got, [2]Key{x, y})
+			return true
+		})
+		if len(got) != len(byVal) || len(got) > 0 && !reflect.DeepEqual(got, byVal) {
+			t.Fatalf("RangeByValue(%v, %v) = %v; want %v", lo, hi, got, byVal)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package avl
+
+import "fmt"
+
+// 将已按键排好序的节点序列v[lo:hi]构建为平衡的AVL树，返回根节点。
+// 节点的高度、标记和线索都会被重新设置，v之外的相邻节点作为首尾节点的线索。
+func build(v []*Node, lo, hi int) *Node {
+	if lo >= hi {
+		return null
+	}
+	m := (lo + hi) / 2
+	p := v[m]
+	l, r := build(v, lo, m), build(v, m+1, hi)
+	p.mrk = 0
+	if l != null {
+		p.ptA, p.mrk = l, p.mrk|2
+	} else if m > 0 {
+		p.ptA = v[m-1]
+	} else {
+		p.ptA = nil
+	}
+	if r != null {
+		p.ptB, p.mrk = r, p.mrk|1
+	} else if m+1 < len(v) {
+		p.ptB = v[m+1]
+	} else {
+		p.ptB = nil
+	}
+	p.hgt = max(l.hgt, r.hgt) + 1
+	return p
+}
+
+// 检查节点是否按键升序排列，再由节点构建AVL树
+func fromNodes(list []*Node) (*AVL, error) {
+	for i := 1; i < len(list); i++ {
+		if compare(&list[i].item.Key, &list[i-1].item.Key) < 0 {
+			return nil, fmt.Errorf("avl: 第%d个键小于前一个键，输入未按升序排列", i)
+		}
+	}
+	p := New()
+	p.root = build(list, 0, len(list))
+	return p, nil
+}
+
+// 由按键升序排列的键值对在O(n)时间内构建AVL树，vals为nil时所有值均为nil。
+// 允许存在相同的键（如同Insert）；键未排序或长度不一致时返回错误。
+func FromSorted(keys []Key, vals []typeC) (*AVL, error) {
+	if vals != nil && len(vals) != len(keys) {
+		return nil, fmt.Errorf("avl: 键的数目%d与值的数目%d不一致", len(keys), len(vals))
+	}
+	list := newEntries(len(keys))
+	for i := range keys {
+		list[i].item.Key = keys[i]
+		if vals != nil {
+			list[i].item.Val = vals[i]
+		}
+	}
+	return fromNodes(list)
+}
+
+// 同FromSorted，但键值对由next依次给出，ok为false时表示序列结束
+func FromSortedFunc(next func() (k Key, v typeC, ok bool)) (*AVL, error) {
+	var (
+		keys []Key
+		vals []typeC
+	)
+	for {
+		k, x, ok := next()
+		if !ok {
+			break
+		}
+		keys, vals = append(keys, k), append(vals, x)
+	}
+	return FromSorted(keys, vals)
+}
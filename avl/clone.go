@@ -0,0 +1,67 @@
+package avl
+
+// 复制以p为根的子树并按中序将新节点追加到v中，f不为nil时用于复制值；新节点的线索由thread设置
+func clone(p *Node, f func(typeC) typeC, v *[]*Node) *Node {
+	if p == null {
+		return null
+	}
+	q := p.copy()
+	if f != nil {
+		q.item.Val = f(p.item.Val)
+	}
+	l := clone(p.Lson(), f, v)
+	*v = append(*v, q)
+	r := clone(p.Rson(), f, v)
+	if l != null {
+		q.ptA = l
+	}
+	if r != null {
+		q.ptB = r
+	}
+	return q
+}
+
+// 按中序序列为没有子节点的一侧设置前驱、后继线索
+func thread(v []*Node) {
+	for i, p := range v {
+		if p.mrk&2 == 0 {
+			if p.ptA = nil; i > 0 {
+				p.ptA = v[i-1]
+			}
+		}
+		if p.mrk&1 == 0 {
+			if p.ptB = nil; i+1 < len(v) {
+				p.ptB = v[i+1]
+			}
+		}
+	}
+}
+
+// 在O(n)时间内复制整棵树，保持原有的形状、高度与线索；f不为nil时用于复制值，否则新树与原树共享值
+func (this *AVL) Clone(f func(typeC) typeC) *AVL {
+	var v []*Node
+	p := New()
+	p.root = clone(this.root, f, &v)
+	thread(v)
+	return p
+}
+
+// 删除全部键值对
+func (this *AVL) Clear() {
+	this.root = null
+	this.mod++
+}
+
+// 判断两棵树是否按键的顺序包含相同的键值对，与树的形状无关；eq为nil时只比较键
+func (this *AVL) Equal(other *AVL, eq func(a, b typeC) bool) bool {
+	p, q := this.Min(), other.Min()
+	for ; p != nil && q != nil; p, q = p.Next(), q.Next() {
+		if compare(&p.item.Key, &q.item.Key) != 0 {
+			return false
+		}
+		if eq != nil && !eq(p.item.Val, q.item.Val) {
+			return false
+		}
+	}
+	return p == nil && q == nil
+}
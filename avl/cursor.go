@@ -0,0 +1,139 @@
+package avl
+
+// 查找节点p并记录从x开始的路径，存在相同的键时仍能精确定位到p
+func (this *trace) Locate(x **Node, p *Node, i int) bool {
+	q := *x
+	if q == null || i >= depth {
+		return false
+	}
+	this.st[i] = x
+	switch compare(&p.item.Key, &q.item.Key) {
+	case -1:
+		return q.mrk&2 != 0 && this.Locate(&q.ptA, p, i+1)
+	case +1:
+		return q.mrk&1 != 0 && this.Locate(&q.ptB, p, i+1)
+	}
+	if q == p {
+		this.sp = i + 1
+		return true
+	}
+	if q.mrk&2 != 0 && this.Locate(&q.ptA, p, i+1) {
+		return true
+	}
+	return q.mrk&1 != 0 && this.Locate(&q.ptB, p, i+1)
+}
+
+// 返回第一个键不小于k的节点，没有时返回nil
+func (this *AVL) ceil(k *Key) *Node {
+	var q *Node
+	for p := this.root; p != null; {
+		if compare(&p.item.Key, k) >= 0 {
+			q, p = p, p.Lson()
+		} else {
+			p = p.Rson()
+		}
+	}
+	return q
+}
+
+// AVL树的游标，指向树中的一个节点，或者处于无效位置
+type Cursor struct {
+	tree *AVL
+	node *Node
+	mod  uint64 // 定位时容器的修改次数
+}
+
+// 创建本树的游标，初始处于无效位置
+func (this *AVL) Cursor() *Cursor {
+	return &Cursor{tree: this}
+}
+
+// 移动到第一个键不小于(n, s)的节点，返回游标是否有效
+func (this *Cursor) Seek(n typeA, s typeB) bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.ceil(&Key{n, s})
+	return this.node != nil
+}
+
+// 移动到最小键的节点，返回游标是否有效
+func (this *Cursor) SeekFirst() bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.Min()
+	return this.node != nil
+}
+
+// 移动到最大键的节点，返回游标是否有效
+func (this *Cursor) SeekLast() bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.Max()
+	return this.node != nil
+}
+
+// 移动到后继节点，返回游标是否有效
+func (this *Cursor) Next() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = this.node.Next()
+	}
+	return this.node != nil
+}
+
+// 移动到前驱节点，返回游标是否有效
+func (this *Cursor) Prev() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = this.node.Prev()
+	}
+	return this.node != nil
+}
+
+// 游标是否指向树中的节点
+func (this *Cursor) Valid() bool {
+	return this.node != nil
+}
+
+// 返回当前节点的键，游标无效时panic
+func (this *Cursor) Key() (typeA, typeB) {
+	this.sync()
+	return this.node.Key()
+}
+
+// 返回当前节点的值，游标无效时panic
+func (this *Cursor) Value() typeC {
+	this.sync()
+	return this.node.Val()
+}
+
+// 设置当前节点的值，游标无效时panic
+func (this *Cursor) SetValue(v typeC) {
+	this.sync()
+	this.node.Set(v)
+}
+
+// 删除当前节点并移动到其后继节点，返回游标是否有效；游标无效时什么也不做
+func (this *Cursor) Delete() bool {
+	p := this.node
+	if p == nil {
+		return false
+	}
+	this.sync()
+	t := this.tree
+	if debug {
+		defer t.check()
+	}
+	this.node = p.Next()
+	tr := trace{rot: &t.rot}
+	if tr.Locate(&t.root, p, 0) {
+		tr.Remove()
+		t.mod++
+	}
+	this.mod = t.mod
+	return this.node != nil
+}
+
+// 确认游标定位以来容器没有被游标以外的途径插入或删除过节点，否则panic
+func (this *Cursor) sync() {
+	if this.mod != this.tree.mod {
+		panic(ErrModified)
+	}
+}
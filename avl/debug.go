@@ -0,0 +1,6 @@
+//go:build containerdebug
+
+package avl
+
+// 使用containerdebug构建标签编译时开启调试模式，每次修改后都会调用Verify检查不变式
+const debug = true
@@ -0,0 +1,78 @@
+package avl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/hydra13142/container"
+)
+
+// 未提供标签函数时使用的节点标签
+func keyLabel(p *Node) string {
+	return fmt.Sprintf("%d, %s", p.item.Key.N, p.item.Key.S)
+}
+
+// 按中序为每个节点编号，用于生成图中节点的名称
+func (this *AVL) number() ([]*Node, map[interface{}]int) {
+	var v []*Node
+	for p := this.Min(); p != nil; p = p.Next() {
+		v = append(v, p)
+	}
+	return v, container.Number(len(v), func(i int) interface{} { return v[i] })
+}
+
+// 以Graphviz的DOT格式输出树的结构，f生成节点的标签，为nil时使用键。
+// 实线为子节点，虚线为线索，节点旁标注其高度。
+func (this *AVL) WriteDOT(w io.Writer, f func(*Node) string) error {
+	if f == nil {
+		f = keyLabel
+	}
+	v, m := this.number()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph avl {\n\tnode [shape=box];\n")
+	for i, p := range v {
+		fmt.Fprintf(bw, "\tn%d [label=\"%s\", xlabel=\"h=%d\"];\n", i, container.DotEscape(f(p)), p.hgt)
+	}
+	for i, p := range v {
+		if l := p.Lson(); l != null {
+			fmt.Fprintf(bw, "\tn%d -> n%d [label=\"L\"];\n", i, m[l])
+		} else if p.ptA != nil {
+			fmt.Fprintf(bw, "\tn%d -> n%d [style=dashed, color=gray, constraint=false];\n", i, m[p.ptA])
+		}
+		if r := p.Rson(); r != null {
+			fmt.Fprintf(bw, "\tn%d -> n%d [label=\"R\"];\n", i, m[r])
+		} else if p.ptB != nil {
+			fmt.Fprintf(bw, "\tn%d -> n%d [style=dashed, color=gray, constraint=false];\n", i, m[p.ptB])
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// 以Mermaid流程图的格式输出树的结构，f生成节点的标签，为nil时使用键。
+// 实线为子节点，虚线为线索，节点标签后附其高度。
+func (this *AVL) WriteMermaid(w io.Writer, f func(*Node) string) error {
+	if f == nil {
+		f = keyLabel
+	}
+	v, m := this.number()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "flowchart TD\n")
+	for i, p := range v {
+		fmt.Fprintf(bw, "\tn%d[\"%s<br/>h=%d\"]\n", i, container.MermaidEscape(f(p)), p.hgt)
+	}
+	for i, p := range v {
+		if l := p.Lson(); l != null {
+			fmt.Fprintf(bw, "\tn%d -->|L| n%d\n", i, m[l])
+		} else if p.ptA != nil {
+			fmt.Fprintf(bw, "\tn%d -.->|prev| n%d\n", i, m[p.ptA])
+		}
+		if r := p.Rson(); r != null {
+			fmt.Fprintf(bw, "\tn%d -->|R| n%d\n", i, m[r])
+		} else if p.ptB != nil {
+			fmt.Fprintf(bw, "\tn%d -.->|next| n%d\n", i, m[p.ptB])
+		}
+	}
+	return bw.Flush()
+}
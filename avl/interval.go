@@ -0,0 +1,174 @@
+package avl
+
+import "fmt"
+
+// 区间树中的闭区间[Lo, Hi]，以左端点和名称标识
+type Interval struct {
+	Lo   typeA
+	Hi   typeA
+	Name typeB
+	Val  typeC
+}
+
+// 区间树节点中保存的值，max为子树中右端点的最大值
+type span struct {
+	hi  typeA
+	max typeA
+	val typeC
+}
+
+// 区间树，以区间的左端点和名称为键的AVL树，每个节点额外记录子树中右端点的最大值
+type IntervalTree struct {
+	root *Node
+	rot  rotation
+	mod  uint64
+}
+
+// 由子节点重新计算节点记录的右端点最大值
+func stretch(p *Node) {
+	s := p.item.Val.(*span)
+	s.max = s.hi
+	if l := p.Lson(); l != null {
+		if m := l.item.Val.(*span).max; m > s.max {
+			s.max = m
+		}
+	}
+	if r := p.Rson(); r != null {
+		if m := r.item.Val.(*span).max; m > s.max {
+			s.max = m
+		}
+	}
+}
+
+// 节点所表示的区间
+func interval(p *Node) Interval {
+	s := p.item.Val.(*span)
+	return Interval{p.item.Key.N, s.hi, p.item.Key.S, s.val}
+}
+
+// 创建一个区间树
+func NewIntervalTree() *IntervalTree {
+	p := new(IntervalTree)
+	p.root = null
+	return p
+}
+
+// 加入区间[lo, hi]，lo大于hi时交换两者；左端点和名称相同的区间已存在时，更新其右端点和值
+func (this *IntervalTree) Insert(lo, hi typeA, s typeB, v typeC) {
+	if debug {
+		defer this.check()
+	}
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	tr := trace{rot: &this.rot, fix: stretch}
+	if tr.Update(&this.root, &Key{lo, s}, &span{hi, hi, v}) {
+		this.mod++
+	}
+}
+
+// 删除左端点为lo、名称为s的区间，返回该区间是否存在
+func (this *IntervalTree) Delete(lo typeA, s typeB) bool {
+	if debug {
+		defer this.check()
+	}
+	tr := trace{rot: &this.rot, fix: stretch}
+	if !tr.Delete(&this.root, &Key{lo, s}) {
+		return false
+	}
+	this.mod++
+	return true
+}
+
+// 查找左端点为lo、名称为s的区间
+func (this *IntervalTree) Search(lo typeA, s typeB) (Interval, bool) {
+	k := &Key{lo, s}
+	for p := this.root; p != null; {
+		switch compare(k, &p.item.Key) {
+		case -1:
+			p = p.Lson()
+		case +1:
+			p = p.Rson()
+		default:
+			return interval(p), true
+		}
+	}
+	return Interval{}, false
+}
+
+// 按左端点的顺序对子树中与[lo, hi]相交的区间调用f，f返回false时停止并返回false。
+// 右端点最大值小于lo的子树，以及左端点大于hi的节点的右子树都被跳过。
+func overlap(p *Node, lo, hi typeA, f func(Interval) bool) bool {
+	if p == null || p.item.Val.(*span).max < lo {
+		return true
+	}
+	if !overlap(p.Lson(), lo, hi, f) {
+		return false
+	}
+	if p.item.Key.N > hi {
+		return true
+	}
+	if p.item.Val.(*span).hi >= lo && !f(interval(p)) {
+		return false
+	}
+	return overlap(p.Rson(), lo, hi, f)
+}
+
+// 按左端点的顺序对所有包含点x的区间调用f，f返回false时停止，其间修改本树会引发panic。耗时O(log n + m)，m为结果的数目
+func (this *IntervalTree) Stabbing(x typeA, f func(Interval) bool) {
+	overlap(this.root, x, x, this.guard(f))
+}
+
+// 按左端点的顺序对所有与[lo, hi]相交的区间调用f，f返回false时停止，其间修改本树会引发panic。耗时O(log n + m)，m为结果的数目
+func (this *IntervalTree) Overlapping(lo, hi typeA, f func(Interval) bool) {
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	overlap(this.root, lo, hi, this.guard(f))
+}
+
+// 检查子树中各节点记录的右端点最大值，返回子树的右端点最大值
+func stretched(p *Node) (typeA, error) {
+	s := p.item.Val.(*span)
+	if s.hi < p.item.Key.N {
+		return 0, fmt.Errorf("avl: 区间(%d, %s)的右端点%d小于左端点", p.item.Key.N, p.item.Key.S, s.hi)
+	}
+	m := s.hi
+	for _, c := range [2]*Node{p.Lson(), p.Rson()} {
+		if c == null {
+			continue
+		}
+		x, err := stretched(c)
+		if err != nil {
+			return 0, err
+		}
+		if x > m {
+			m = x
+		}
+	}
+	if m != s.max {
+		return 0, fmt.Errorf("avl: 区间(%d, %s)记录的右端点最大值为%d，实际为%d", p.item.Key.N, p.item.Key.S, s.max, m)
+	}
+	return m, nil
+}
+
+// 检查区间树的各项不变式：AVL树本身的不变式以及各节点记录的右端点最大值。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *IntervalTree) Verify() error {
+	t := AVL{root: this.root}
+	if err := t.Verify(); err != nil {
+		return err
+	}
+	if this.root == null {
+		return nil
+	}
+	_, err := stretched(this.root)
+	return err
+}
+
+// 调试模式下，每次修改后检查不变式，不满足时立即panic
+func (this *IntervalTree) check() {
+	if err := this.Verify(); err != nil {
+		panic(err)
+	}
+}
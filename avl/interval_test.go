@@ -0,0 +1,85 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 以线性扫描作为模型检查区间树的插入、删除、刺穿查询和相交查询
+func TestIntervalTree(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	tr := NewIntervalTree()
+	ref := map[Key]Interval{}
+	// 按左端点和名称的顺序列出模型中与[lo, hi]相交的区间
+	scan := func(lo, hi int64) []Interval {
+		var v []Interval
+		for _, x := range ref {
+			if x.Lo <= hi && x.Hi >= lo {
+				v = append(v, x)
+			}
+		}
+		sort.Slice(v, func(i, j int) bool {
+			return v[i].Lo < v[j].Lo || v[i].Lo == v[j].Lo && v[i].Name < v[j].Name
+		})
+		return v
+	}
+	collect := func(q func(func(Interval) bool)) []Interval {
+		var v []Interval
+		q(func(x Interval) bool {
+			v = append(v, x)
+			return true
+		})
+		return v
+	}
+	for it := 0; it < 5000; it++ {
+		lo, hi := rd.Int63n(100), rd.Int63n(100)
+		name := string(rune('a' + rd.Intn(3)))
+		switch rd.Intn(4) {
+		case 0, 1:
+			tr.Insert(lo, hi, name, it)
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			ref[Key{lo, name}] = Interval{lo, hi, name, it}
+		case 2:
+			_, ok := ref[Key{lo, name}]
+			if tr.Delete(lo, name) != ok {
+				t.Fatalf("Delete(%d, %s) = %v; want %v", lo, name, !ok, ok)
+			}
+			delete(ref, Key{lo, name})
+		case 3:
+			x, ok := tr.Search(lo, name)
+			if y, found := ref[Key{lo, name}]; ok != found || x != y {
+				t.Fatalf("Search(%d, %s) = %v, %v; want %v, %v", lo, name, x, ok, y, found)
+			}
+		}
+		if err := tr.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		x := rd.Int63n(110) - 5
+		if got, want := collect(func(f func(Interval) bool) { tr.Stabbing(x, f) }), scan(x, x); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Stabbing(%d) = %v; want %v", x, got, want)
+		}
+		a, b := rd.Int63n(110)-5, rd.Int63n(110)-5
+		want := scan(a, b)
+		if a > b {
+			want = scan(b, a)
+		}
+		if got := collect(func(f func(Interval) bool) { tr.Overlapping(a, b, f) }); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Overlapping(%d, %d) = %v; want %v", a, b, got, want)
+		}
+		// f返回false时立即停止
+		if len(want) > 1 {
+			var got []Interval
+			tr.Overlapping(a, b, func(x Interval) bool {
+				got = append(got, x)
+				return len(got) < 2
+			})
+			if !reflect.DeepEqual(got, want[:2]) {
+				t.Fatalf("Overlapping(%d, %d) stopped after %v; want %v", a, b, got, want[:2])
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package avl
+
+// 将l、m、r合并为一棵AVL树并返回根节点，要求l的键均不大于m，r的键均不小于m。
+// 沿较高一侧的边缘下降到高度相近处挂上m，再逐层旋转恢复平衡。
+// 调用者应保证l的最大节点的后继线索、r的最小节点的前驱线索均已指向m；
+// 合并后整棵树最左、最右两个节点朝外的线索不作处理。
+func join(l, m, r *Node, c *rotation) *Node {
+	switch {
+	case l.hgt > r.hgt+1:
+		t := l.Rson()
+		x := join(t, m, r, c)
+		if t == null {
+			m.ptA = l
+		}
+		l.ptB, l.mrk = x, l.mrk|1
+		return balance(l, c, nil)
+	case r.hgt > l.hgt+1:
+		t := r.Lson()
+		x := join(l, m, t, c)
+		if t == null {
+			m.ptB = r
+		}
+		r.ptA, r.mrk = x, r.mrk|2
+		return balance(r, c, nil)
+	}
+	m.mrk = 0
+	if l != null {
+		m.ptA, m.mrk = l, m.mrk|2
+	}
+	if r != null {
+		m.ptB, m.mrk = r, m.mrk|1
+	}
+	m.hgt = max(l.hgt, r.hgt) + 1
+	return m
+}
+
+// 将以t为根的树分割为键小于k和键不小于k的两棵树。
+// 分割后两棵树内部的中序相邻关系都与原树相同，因此线索只有两端需要修正。
+func split(t *Node, k *Key, c *rotation) (*Node, *Node) {
+	if t == null {
+		return null, null
+	}
+	l, r := t.Lson(), t.Rson()
+	if compare(&t.item.Key, k) < 0 {
+		a, b := split(r, k, c)
+		return join(l, t, a, c), b
+	}
+	a, b := split(l, k, c)
+	return a, join(b, t, r, c)
+}
+
+// 移除树中最小的节点，返回剩余部分的根节点和被移除的节点
+func popMin(t *Node, c *rotation) (*Node, *Node) {
+	l := t.Lson()
+	if l == null {
+		return t.Rson(), t
+	}
+	x, m := popMin(l, c)
+	if x == null {
+		t.ptA, t.mrk = m, t.mrk&1
+	} else {
+		t.ptA = x
+	}
+	return balance(t, c, nil), m
+}
+
+// 合并两棵树，要求l的键均不大于r的键
+func join2(l, r *Node, c *rotation) *Node {
+	if l == null {
+		return r
+	}
+	if r == null {
+		return l
+	}
+	r, m := popMin(r, c)
+	p := l
+	for p.mrk&1 != 0 {
+		p = p.ptB
+	}
+	p.ptB = m
+	return join(l, m, r, c)
+}
+
+// 将整棵树最左节点的前驱线索和最右节点的后继线索置为nil
+func seal(t *Node) {
+	if t == null {
+		return
+	}
+	p := t
+	for p.mrk&2 != 0 {
+		p = p.ptA
+	}
+	p.ptA = nil
+	for p = t; p.mrk&1 != 0; p = p.ptB {
+	}
+	p.ptB = nil
+}
+
+// 统计子树的节点数目
+func count(t *Node) int {
+	if t == null {
+		return 0
+	}
+	return count(t.Lson()) + count(t.Rson()) + 1
+}
+
+// 删除键在[lo, hi)范围内的所有键值对，返回删除的数目。
+// 通过两次分割和一次合并完成，除统计数目外耗时为O(log n)。
+func (this *AVL) DeleteRange(lo, hi Key) int {
+	if debug {
+		defer this.check()
+	}
+	if compare(&lo, &hi) >= 0 {
+		return 0
+	}
+	l, x := split(this.root, &lo, &this.rot)
+	m, r := split(x, &hi, &this.rot)
+	this.root = join2(l, r, &this.rot)
+	seal(this.root)
+	n := count(m)
+	if n > 0 {
+		this.mod++
+	}
+	return n
+}
+
+// 删除所有使f返回true的键值对，返回删除的数目。
+// f按键的升序被调用，其间修改本树会引发panic；剩余的节点会在O(n)时间内重建为平衡树。
+func (this *AVL) DeleteFunc(f func(*Node) bool) int {
+	if debug {
+		defer this.check()
+	}
+	var v []*Node
+	n := 0
+	f = this.guard(f)
+	for p := this.Min(); p != nil; p = p.Next() {
+		if f(p) {
+			n++
+		} else {
+			v = append(v, p)
+		}
+	}
+	if n > 0 {
+		this.root = build(v, 0, len(v))
+		this.mod++
+	}
+	return n
+}
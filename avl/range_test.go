@@ -0,0 +1,143 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 按键的顺序列出容器中的全部键
+func dump(t *AVL) []Key {
+	var v []Key
+	c := t.Cursor()
+	for ok := c.SeekFirst(); ok; ok = c.Next() {
+		n, s := c.Key()
+		v = append(v, Key{n, s})
+	}
+	return v
+}
+
+func TestDeleteRange(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	key := func() Key {
+		return Key{rd.Int63n(40), string(rune('a' + rd.Intn(3)))}
+	}
+	for it := 0; it < 200; it++ {
+		tr := New()
+		var ref []Key
+		for i, n := 0, rd.Intn(300); i < n; i++ {
+			k := key()
+			tr.Insert(k.N, k.S, nil)
+			ref = append(ref, k)
+		}
+		for j := 0; j < 10; j++ {
+			lo, hi := key(), key()
+			lo.N, hi.N = lo.N-2, hi.N+2
+			n := tr.DeleteRange(lo, hi)
+			var rest []Key
+			for _, k := range ref {
+				if compare(&k, &lo) < 0 || compare(&k, &hi) >= 0 {
+					rest = append(rest, k)
+				}
+			}
+			if n != len(ref)-len(rest) {
+				t.Fatalf("DeleteRange(%v, %v) = %d; want %d", lo, hi, n, len(ref)-len(rest))
+			}
+			ref = rest
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(ref, func(i, j int) bool { return compare(&ref[i], &ref[j]) < 0 })
+			got := dump(tr)
+			if len(got) != len(ref) {
+				t.Fatalf("%d keys left; want %d", len(got), len(ref))
+			}
+			for i := range got {
+				if got[i] != ref[i] {
+					t.Fatalf("key %d is %v; want %v", i, got[i], ref[i])
+				}
+			}
+			for i := 0; i < 20; i++ {
+				k := key()
+				tr.Insert(k.N, k.S, nil)
+				ref = append(ref, k)
+			}
+		}
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	cases := []struct {
+		name string
+		f    func(k Key, v int) bool
+	}{
+		{"none", func(k Key, v int) bool { return false }},
+		{"all", func(k Key, v int) bool { return true }},
+		{"min", func(k Key, v int) bool { return k == Key{0, "a"} }},
+		{"max", func(k Key, v int) bool { return k == Key{99, "b"} }},
+		{"all but max", func(k Key, v int) bool { return k != Key{99, "b"} }},
+		{"prefix", func(k Key, v int) bool { return k.N < 30 }},
+		{"odd values", func(k Key, v int) bool { return v%2 == 1 }},
+		{"one string", func(k Key, v int) bool { return k.S == "a" }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := New()
+			var ref []Key
+			for i := 0; i < 200; i++ {
+				k := Key{int64(i / 2), string(rune('a' + i%2))}
+				tr.Update(k.N, k.S, i)
+				ref = append(ref, k)
+			}
+			var seen, want []Key
+			for i, k := range ref {
+				if !c.f(k, i) {
+					want = append(want, k)
+				}
+			}
+			n := tr.DeleteFunc(func(p *Node) bool {
+				k := p.item.Key
+				if len(seen) > 0 && compare(&seen[len(seen)-1], &k) >= 0 {
+					t.Fatalf("DeleteFunc called f on %v after %v", k, seen[len(seen)-1])
+				}
+				seen = append(seen, k)
+				return c.f(k, p.Val().(int))
+			})
+			if len(seen) != len(ref) {
+				t.Fatalf("f was called %d times; want %d", len(seen), len(ref))
+			}
+			if n != len(ref)-len(want) {
+				t.Fatalf("DeleteFunc = %d; want %d", n, len(ref)-len(want))
+			}
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if got := dump(tr); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%d keys left, %v; want %v", len(got), got, want)
+			}
+			// 删除之后的树仍然可以正常插入
+			tr.Update(-1, "", -1)
+			tr.Update(100, "", -1)
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDeleteFuncModify(t *testing.T) {
+	tr := New()
+	for i := int64(0); i < 10; i++ {
+		tr.Update(i, "", nil)
+	}
+	defer func() {
+		if recover() != ErrModified {
+			t.Fatal("inserting from the DeleteFunc callback did not panic with ErrModified")
+		}
+	}()
+	tr.DeleteFunc(func(p *Node) bool {
+		tr.Update(p.item.Key.N+100, "", nil)
+		return false
+	})
+}
@@ -0,0 +1,19 @@
+package avl
+
+import "strings"
+
+// 按键的顺序对第一部分为n、第二部分以prefix为前缀的所有键值对调用f，f返回false时停止，其间修改本树会引发panic。
+// 先下降到第一个不小于(n, prefix)的节点，再逐个向后，遇到第一个不匹配的键即停止，耗时O(log n + m)
+func (this *AVL) ScanPrefix(n typeA, prefix typeB, f func(*Node) bool) {
+	f = this.guard(f)
+	for p := this.ceil(&Key{n, prefix}); p != nil; p = p.Next() {
+		if p.item.Key.N != n || !strings.HasPrefix(p.item.Key.S, prefix) || !f(p) {
+			return
+		}
+	}
+}
+
+// 按键的顺序对第一部分为n的所有键值对调用f，f返回false时停止，其间修改本树会引发panic
+func (this *AVL) ScanN(n typeA, f func(*Node) bool) {
+	this.ScanPrefix(n, "", f)
+}
@@ -0,0 +1,186 @@
+package avl
+
+// 只保存键的有序集合。与AVL共用平衡代码，但节点只有键而没有值（不属于entry）
+type Set struct {
+	tree AVL
+	size int
+}
+
+// 创建一个集合，并加入给定的键
+func NewSet(keys ...Key) *Set {
+	p := new(Set)
+	p.tree.root = null
+	for i := range keys {
+		p.Add(keys[i].N, keys[i].S)
+	}
+	return p
+}
+
+// 由升序排列且互不相同的键构建集合
+func fromKeys(v []Key) *Set {
+	nodes := make([]Node, len(v))
+	list := make([]*Node, len(v))
+	for i := range v {
+		nodes[i].item.Key = v[i]
+		list[i] = &nodes[i]
+	}
+	t, _ := fromNodes(list)
+	return &Set{tree: *t, size: len(v)}
+}
+
+// 加入键，返回键原先是否不在集合中
+func (this *Set) Add(n typeA, s typeB) bool {
+	if debug {
+		defer this.tree.check()
+	}
+	tr := trace{rot: &this.tree.rot}
+	if tr.Search(&this.tree.root, &Key{n, s}) {
+		return false
+	}
+	tr.attach(&Node{0, 1, nil, nil, item{Key: Key{n, s}}})
+	this.tree.mod++
+	this.size++
+	return true
+}
+
+// 移除键，返回键原先是否在集合中
+func (this *Set) Remove(n typeA, s typeB) bool {
+	if _, ok := this.tree.Delete(n, s); !ok {
+		return false
+	}
+	this.size--
+	return true
+}
+
+// 键是否在集合中
+func (this *Set) Contains(n typeA, s typeB) bool {
+	return this.tree.Search(n, s) != nil
+}
+
+// 返回集合中键的数目
+func (this *Set) Len() int {
+	return this.size
+}
+
+// 返回最小的键，集合为空时ok为false
+func (this *Set) Min() (k Key, ok bool) {
+	if p := this.tree.Min(); p != nil {
+		return p.item.Key, true
+	}
+	return Key{}, false
+}
+
+// 返回最大的键，集合为空时ok为false
+func (this *Set) Max() (k Key, ok bool) {
+	if p := this.tree.Max(); p != nil {
+		return p.item.Key, true
+	}
+	return Key{}, false
+}
+
+// 按升序对键在[lo, hi)范围内的每个键调用f，f返回false时停止，其间修改本集合会引发panic
+func (this *Set) Range(lo, hi Key, f func(Key) bool) {
+	g := this.tree.guard(func(p *Node) bool { return f(p.item.Key) })
+	for p := this.tree.ceil(&lo); p != nil && compare(&p.item.Key, &hi) < 0; p = p.Next() {
+		if !g(p) {
+			return
+		}
+	}
+}
+
+// 按升序对每个键调用f，f返回false时停止，其间修改本集合会引发panic
+func (this *Set) Each(f func(Key) bool) {
+	g := this.tree.guard(func(p *Node) bool { return f(p.item.Key) })
+	for p := this.tree.Min(); p != nil; p = p.Next() {
+		if !g(p) {
+			return
+		}
+	}
+}
+
+// 按升序返回全部的键
+func (this *Set) Keys() []Key {
+	v := make([]Key, 0, this.size)
+	for p := this.tree.Min(); p != nil; p = p.Next() {
+		v = append(v, p.item.Key)
+	}
+	return v
+}
+
+// 按升序同时遍历两个集合，只在a中的键在onlyA为true时保留，只在b中的键在onlyB为true时保留，
+// 两者共有的键在both为true时保留，再由保留的键在O(n+m)时间内构建新的集合
+func merge(a, b *Set, onlyA, onlyB, both bool) *Set {
+	var v []Key
+	p, q := a.tree.Min(), b.tree.Min()
+	for p != nil || q != nil {
+		var c int8
+		switch {
+		case p == nil:
+			c = +1
+		case q == nil:
+			c = -1
+		default:
+			c = compare(&p.item.Key, &q.item.Key)
+		}
+		switch {
+		case c < 0:
+			if onlyA {
+				v = append(v, p.item.Key)
+			}
+			p = p.Next()
+		case c > 0:
+			if onlyB {
+				v = append(v, q.item.Key)
+			}
+			q = q.Next()
+		default:
+			if both {
+				v = append(v, p.item.Key)
+			}
+			p, q = p.Next(), q.Next()
+		}
+	}
+	return fromKeys(v)
+}
+
+// 返回两个集合的并集
+func (this *Set) Union(other *Set) *Set {
+	return merge(this, other, true, true, true)
+}
+
+// 返回两个集合的交集
+func (this *Set) Intersect(other *Set) *Set {
+	return merge(this, other, false, false, true)
+}
+
+// 返回在本集合中而不在other中的键构成的集合
+func (this *Set) Difference(other *Set) *Set {
+	return merge(this, other, true, false, false)
+}
+
+// 返回只在两个集合之一中的键构成的集合
+func (this *Set) SymmetricDifference(other *Set) *Set {
+	return merge(this, other, true, true, false)
+}
+
+// 本集合的键是否都在other中
+func (this *Set) IsSubset(other *Set) bool {
+	if this.size > other.size {
+		return false
+	}
+	q := other.tree.Min()
+	for p := this.tree.Min(); p != nil; p = p.Next() {
+		for q != nil && compare(&q.item.Key, &p.item.Key) < 0 {
+			q = q.Next()
+		}
+		if q == nil || compare(&q.item.Key, &p.item.Key) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 两个集合是否包含相同的键
+func (this *Set) Equal(other *Set) bool {
+	return this.size == other.size && this.IsSubset(other)
+}
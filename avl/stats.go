@@ -0,0 +1,46 @@
+package avl
+
+import "unsafe"
+
+// 旋转次数的累计
+type rotation struct {
+	single uint64
+	double uint64
+}
+
+// 树的结构统计信息
+type Stats struct {
+	Count   int     // 键值对的数目
+	Height  int     // 树的高度，空树为0
+	AvgPath float64 // 平均查找路径长度，即各节点深度（根节点为1）的平均值
+	Memory  uintptr // 节点占用内存的估计值，不含键中的字符串和值引用的数据
+	Single  uint64  // 自创建以来累计的单旋转次数（包括删除时将节点旋转为叶节点的次数）
+	Double  uint64  // 自创建以来累计的双旋转次数
+}
+
+// 累计子树中节点的数目和深度之和
+func (this *Stats) walk(p *Node, d int, sum *int) {
+	if p == null {
+		return
+	}
+	this.Count++
+	*sum += d
+	this.walk(p.Lson(), d+1, sum)
+	this.walk(p.Rson(), d+1, sum)
+}
+
+// 返回树的结构统计信息，需要遍历整棵树，耗时O(n)
+func (this *AVL) Stats() Stats {
+	var (
+		s   Stats
+		sum int
+	)
+	s.walk(this.root, 1, &sum)
+	s.Height = int(this.root.hgt)
+	if s.Count > 0 {
+		s.AvgPath = float64(sum) / float64(s.Count)
+	}
+	s.Memory = uintptr(s.Count) * unsafe.Sizeof(entry{})
+	s.Single, s.Double = this.rot.single, this.rot.double
+	return s
+}
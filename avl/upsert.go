@@ -0,0 +1,79 @@
+package avl
+
+// 调用回调函数f，f执行期间若树的节点被插入或删除过则panic
+func (this *AVL) call(f func()) {
+	m := this.mod
+	f()
+	if this.mod != m {
+		panic(ErrModified)
+	}
+}
+
+// 插入或更新键值对，返回原有的值以及键是否已存在
+func (this *AVL) Put(n typeA, s typeB, v typeC) (old typeC, replaced bool) {
+	if debug {
+		defer this.check()
+	}
+	k := &Key{n, s}
+	tr := trace{rot: &this.rot}
+	if tr.Search(&this.root, k) {
+		p := *tr.st[tr.sp-1]
+		old, p.item.Val = p.item.Val, v
+		return old, true
+	}
+	tr.Attach(k, v)
+	this.mod++
+	return nil, false
+}
+
+// 键已存在时返回其值，loaded为true；否则插入f返回的值并返回之，loaded为false。
+// f只在键不存在时被调用一次，其间修改本树会引发panic
+func (this *AVL) GetOrInsert(n typeA, s typeB, f func() typeC) (v typeC, loaded bool) {
+	if debug {
+		defer this.check()
+	}
+	k := &Key{n, s}
+	tr := trace{rot: &this.rot}
+	if tr.Search(&this.root, k) {
+		return (*tr.st[tr.sp-1]).item.Val, true
+	}
+	this.call(func() { v = f() })
+	tr.Attach(k, v)
+	this.mod++
+	return v, false
+}
+
+// 以键原有的值（键不存在时old为nil、ok为false）调用f，keep为true时将键的值设为f返回的值，
+// 为false时删除该键（键不存在时什么也不做）。只查找一次，返回键最终的值以及键是否存在。
+// f执行期间修改本树会引发panic
+func (this *AVL) Compute(n typeA, s typeB, f func(old typeC, ok bool) (v typeC, keep bool)) (typeC, bool) {
+	if debug {
+		defer this.check()
+	}
+	var (
+		v    typeC
+		keep bool
+		old  typeC
+	)
+	k := &Key{n, s}
+	tr := trace{rot: &this.rot}
+	ok := tr.Search(&this.root, k)
+	if ok {
+		old = (*tr.st[tr.sp-1]).item.Val
+	}
+	this.call(func() { v, keep = f(old, ok) })
+	switch {
+	case !keep:
+		if ok {
+			tr.Remove()
+			this.mod++
+		}
+		return nil, false
+	case ok:
+		(*tr.st[tr.sp-1]).item.Val = v
+	default:
+		tr.Attach(k, v)
+		this.mod++
+	}
+	return v, true
+}
@@ -0,0 +1,75 @@
+package avl
+
+import "fmt"
+
+// 检查以p为根的子树并按中序将节点追加到v中，返回子树的高度
+func verify(p *Node, d int, v *[]*Node) (int8, error) {
+	if p == null {
+		return 0, nil
+	}
+	if d > depth {
+		return 0, fmt.Errorf("avl: 树的深度超过了上限%d，可能存在环", depth)
+	}
+	if p.mrk > 3 {
+		return 0, fmt.Errorf("avl: 节点(%d, %s)的标记%d无效", p.item.Key.N, p.item.Key.S, p.mrk)
+	}
+	l, err := verify(p.Lson(), d+1, v)
+	if err != nil {
+		return 0, err
+	}
+	*v = append(*v, p)
+	r, err := verify(p.Rson(), d+1, v)
+	if err != nil {
+		return 0, err
+	}
+	if l-r > 1 || r-l > 1 {
+		return 0, fmt.Errorf("avl: 节点(%d, %s)失衡，左子树高%d，右子树高%d", p.item.Key.N, p.item.Key.S, l, r)
+	}
+	if h := max(l, r) + 1; p.hgt != h {
+		return 0, fmt.Errorf("avl: 节点(%d, %s)记录的高度为%d，实际为%d", p.item.Key.N, p.item.Key.S, p.hgt, h)
+	}
+	return p.hgt, nil
+}
+
+// 检查AVL树的各项不变式：高度平衡、高度记录、键的顺序以及线索。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *AVL) Verify() error {
+	var v []*Node
+	if null.mrk != 0 || null.hgt != 0 {
+		return fmt.Errorf("avl: 空节点被修改")
+	}
+	if _, err := verify(this.root, 1, &v); err != nil {
+		return err
+	}
+	for i, p := range v {
+		if i > 0 && compare(&v[i-1].item.Key, &p.item.Key) > 0 {
+			return fmt.Errorf("avl: 第%d个节点(%d, %s)的键小于其前驱", i, p.item.Key.N, p.item.Key.S)
+		}
+		if p.mrk&2 == 0 {
+			var q *Node
+			if i > 0 {
+				q = v[i-1]
+			}
+			if p.ptA != q {
+				return fmt.Errorf("avl: 节点(%d, %s)的前驱线索错误", p.item.Key.N, p.item.Key.S)
+			}
+		}
+		if p.mrk&1 == 0 {
+			var q *Node
+			if i+1 < len(v) {
+				q = v[i+1]
+			}
+			if p.ptB != q {
+				return fmt.Errorf("avl: 节点(%d, %s)的后继线索错误", p.item.Key.N, p.item.Key.S)
+			}
+		}
+	}
+	return nil
+}
+
+// 调试模式下，每次修改后检查不变式，不满足时立即panic
+func (this *AVL) check() {
+	if err := this.Verify(); err != nil {
+		panic(err)
+	}
+}
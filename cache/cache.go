@@ -0,0 +1,206 @@
+// cache包提供按LRU或LFU淘汰的缓存：以avl.AVL按键查找，淘汰顺序保存在treap.PQ中，
+// 权值为最后一次访问的时刻（LRU）或命中次数（LFU），权值最小的条目最先被淘汰
+package cache
+
+import (
+	"fmt"
+
+	"github.com/hydra13142/container/avl"
+	"github.com/hydra13142/container/treap"
+)
+
+type typeA = int64
+
+type typeB = string
+
+type typeC = interface{}
+
+// 缓存的键
+type Key = avl.Key
+
+// 淘汰策略
+type Policy uint8
+
+const (
+	LRU Policy = iota // 淘汰最久未被访问的条目
+	LFU               // 淘汰命中次数最少的条目，次数相同时淘汰哪一个不确定
+)
+
+// 缓存的配置，MaxEntries和MaxBytes为0时表示不限制
+type Options struct {
+	Policy     Policy
+	MaxEntries int
+	MaxBytes   int64
+	Size       func(k Key, v typeC) int64 // 条目占用的字节数，MaxBytes不为0时必须提供
+	OnEvict    func(k Key, v typeC)       // 条目因容量限制被淘汰时调用，可以为nil
+}
+
+// 命中、未命中和淘汰的次数
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// 缓存的条目，node为其在淘汰队列中的节点
+type entry struct {
+	key  Key
+	val  typeC
+	size int64
+	hits int64
+	node *treap.Node
+}
+
+// 按LRU或LFU淘汰的缓存，不能被多个协程同时使用
+type Cache struct {
+	opt   Options
+	tree  *avl.AVL  // 键到*entry
+	order *treap.PQ // 以访问时刻或命中次数为权值的*entry
+	tick  int64
+	size  int
+	bytes int64
+	stats Stats
+}
+
+// 按配置创建一个缓存，配置无效时返回错误
+func New(opt Options) (*Cache, error) {
+	if opt.Policy > LFU {
+		return nil, fmt.Errorf("cache: 淘汰策略%d无效", opt.Policy)
+	}
+	if opt.MaxEntries < 0 || opt.MaxBytes < 0 {
+		return nil, fmt.Errorf("cache: 容量不能为负数")
+	}
+	if opt.MaxBytes > 0 && opt.Size == nil {
+		return nil, fmt.Errorf("cache: 按字节数限制容量时必须提供Size函数")
+	}
+	return &Cache{opt: opt, tree: avl.New(), order: treap.NewPQ()}, nil
+}
+
+// 记录一次访问，按淘汰策略更新条目在淘汰队列中的位置
+func (this *Cache) touch(e *entry) {
+	if e.node != nil {
+		this.order.Remove(e.node)
+	}
+	this.tick++
+	e.hits++
+	w := this.tick
+	if this.opt.Policy == LFU {
+		w = e.hits
+	}
+	e.node = this.order.Push(w, e)
+}
+
+// 移除条目
+func (this *Cache) remove(e *entry) {
+	this.order.Remove(e.node)
+	this.tree.Delete(e.key.N, e.key.S)
+	this.size--
+	this.bytes -= e.size
+}
+
+// 容量是否超出限制
+func (this *Cache) over() bool {
+	return this.opt.MaxEntries > 0 && this.size > this.opt.MaxEntries ||
+		this.opt.MaxBytes > 0 && this.bytes > this.opt.MaxBytes
+}
+
+// 不断淘汰权值最小的条目，直到容量不超出限制或淘汰队列为空
+func (this *Cache) evict() {
+	for this.over() {
+		p := this.order.Peek()
+		if p == nil {
+			return
+		}
+		this.drop(p.Val().(*entry))
+	}
+}
+
+// 因容量限制淘汰条目
+func (this *Cache) drop(e *entry) {
+	this.remove(e)
+	this.stats.Evictions++
+	if this.opt.OnEvict != nil {
+		this.opt.OnEvict(e.key, e.val)
+	}
+}
+
+// 返回键对应的值并记录一次访问，计入命中或未命中的次数
+func (this *Cache) Get(n typeA, s typeB) (typeC, bool) {
+	p := this.tree.Search(n, s)
+	if p == nil {
+		this.stats.Misses++
+		return nil, false
+	}
+	e := p.Val().(*entry)
+	this.stats.Hits++
+	this.touch(e)
+	return e.val, true
+}
+
+// 返回键对应的值，既不记录访问也不计入统计
+func (this *Cache) Peek(n typeA, s typeB) (typeC, bool) {
+	if p := this.tree.Search(n, s); p != nil {
+		return p.Val().(*entry).val, true
+	}
+	return nil, false
+}
+
+// 设置键对应的值并记录一次访问，容量超出限制时淘汰其它条目。
+// 单个条目的字节数超出MaxBytes时，只淘汰该条目本身
+func (this *Cache) Put(n typeA, s typeB, v typeC) {
+	var e *entry
+	if p := this.tree.Search(n, s); p != nil {
+		e = p.Val().(*entry)
+		this.bytes -= e.size
+		this.order.Remove(e.node)
+		e.node = nil
+	} else {
+		e = &entry{key: Key{N: n, S: s}}
+		this.tree.Update(n, s, e)
+		this.size++
+	}
+	e.val, e.size = v, 0
+	if this.opt.Size != nil {
+		e.size = this.opt.Size(e.key, v)
+	}
+	this.bytes += e.size
+	if this.opt.MaxBytes > 0 && e.size > this.opt.MaxBytes {
+		this.touch(e)
+		this.drop(e)
+		return
+	}
+	// 先在不含e的淘汰队列中淘汰，以免LFU下刚写入的条目因命中次数少而被淘汰
+	this.evict()
+	this.touch(e)
+}
+
+// 删除键对应的条目，不调用OnEvict，返回被删除的值以及键是否存在
+func (this *Cache) Delete(n typeA, s typeB) (typeC, bool) {
+	p := this.tree.Search(n, s)
+	if p == nil {
+		return nil, false
+	}
+	e := p.Val().(*entry)
+	this.remove(e)
+	return e.val, true
+}
+
+// 返回条目的数目
+func (this *Cache) Len() int {
+	return this.size
+}
+
+// 返回各条目的字节数之和，没有提供Size函数时为0
+func (this *Cache) Bytes() int64 {
+	return this.bytes
+}
+
+// 返回命中、未命中和淘汰的次数
+func (this *Cache) Stats() Stats {
+	return this.stats
+}
+
+// 将命中、未命中和淘汰的次数清零
+func (this *Cache) ResetStats() {
+	this.stats = Stats{}
+}
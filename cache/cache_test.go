@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 测试用例中的一步操作：'p'为Put，'g'为Get，'k'为Peek，'d'为Delete
+type op struct {
+	kind byte
+	n    int64
+	size int64 // Put时条目的字节数
+}
+
+func TestCache(t *testing.T) {
+	size := func(k Key, v typeC) int64 { return v.(int64) }
+	cases := []struct {
+		name  string
+		opt   Options
+		ops   []op
+		keys  []int64 // 最后留在缓存中的键，升序
+		evict []int64 // 按顺序被淘汰的键
+		bytes int64
+		stats Stats
+	}{
+		{
+			name:  "lru",
+			opt:   Options{MaxEntries: 3},
+			ops:   []op{{'p', 1, 0}, {'p', 2, 0}, {'p', 3, 0}, {'g', 1, 0}, {'p', 4, 0}, {'k', 3, 0}, {'p', 5, 0}, {'g', 9, 0}},
+			keys:  []int64{1, 4, 5},
+			evict: []int64{2, 3},
+			stats: Stats{Hits: 1, Misses: 1, Evictions: 2},
+		},
+		{
+			name:  "lru update",
+			opt:   Options{MaxEntries: 2},
+			ops:   []op{{'p', 1, 0}, {'p', 2, 0}, {'p', 1, 0}, {'p', 3, 0}, {'d', 1, 0}, {'p', 4, 0}},
+			keys:  []int64{3, 4},
+			evict: []int64{2},
+			stats: Stats{Evictions: 1},
+		},
+		{
+			name:  "lfu",
+			opt:   Options{Policy: LFU, MaxEntries: 2},
+			ops:   []op{{'p', 1, 0}, {'p', 2, 0}, {'g', 1, 0}, {'g', 1, 0}, {'p', 3, 0}, {'g', 3, 0}, {'g', 3, 0}, {'g', 3, 0}, {'p', 4, 0}},
+			keys:  []int64{3, 4},
+			evict: []int64{2, 1},
+			stats: Stats{Hits: 5, Evictions: 2},
+		},
+		{
+			name:  "lfu new entry",
+			opt:   Options{Policy: LFU, MaxEntries: 2},
+			ops:   []op{{'p', 1, 0}, {'p', 2, 0}, {'g', 1, 0}, {'g', 2, 0}, {'g', 2, 0}, {'p', 3, 0}, {'g', 3, 0}},
+			keys:  []int64{2, 3},
+			evict: []int64{1},
+			stats: Stats{Hits: 4, Evictions: 1},
+		},
+		{
+			name:  "max bytes",
+			opt:   Options{MaxBytes: 10, Size: size},
+			ops:   []op{{'p', 1, 4}, {'p', 2, 4}, {'p', 3, 4}, {'p', 2, 1}, {'p', 4, 6}, {'p', 5, 11}},
+			keys:  []int64{2, 4},
+			evict: []int64{1, 3, 5},
+			bytes: 7,
+			stats: Stats{Evictions: 3},
+		},
+		{
+			name:  "max entries and bytes",
+			opt:   Options{Policy: LFU, MaxEntries: 3, MaxBytes: 10, Size: size},
+			ops:   []op{{'p', 1, 2}, {'g', 1, 0}, {'g', 1, 0}, {'p', 2, 2}, {'p', 3, 2}, {'g', 3, 0}, {'p', 4, 2}, {'p', 5, 8}},
+			keys:  []int64{1, 5},
+			evict: []int64{2, 4, 3},
+			bytes: 10,
+			stats: Stats{Hits: 3, Evictions: 3},
+		},
+	}
+	for _, c := range cases {
+		var ev []int64
+		c.opt.OnEvict = func(k Key, v typeC) { ev = append(ev, k.N) }
+		m, err := New(c.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range c.ops {
+			switch o.kind {
+			case 'p':
+				m.Put(o.n, "", o.size)
+			case 'g':
+				m.Get(o.n, "")
+			case 'k':
+				m.Peek(o.n, "")
+			case 'd':
+				m.Delete(o.n, "")
+			}
+		}
+		var keys []int64
+		for i := int64(0); i < 10; i++ {
+			if _, ok := m.Peek(i, ""); ok {
+				keys = append(keys, i)
+			}
+		}
+		if !reflect.DeepEqual(keys, c.keys) {
+			t.Errorf("%s: keys = %v; want %v", c.name, keys, c.keys)
+		}
+		if !reflect.DeepEqual(ev, c.evict) {
+			t.Errorf("%s: evicted %v; want %v", c.name, ev, c.evict)
+		}
+		if m.Len() != len(c.keys) || m.Bytes() != c.bytes {
+			t.Errorf("%s: Len, Bytes = %d, %d; want %d, %d", c.name, m.Len(), m.Bytes(), len(c.keys), c.bytes)
+		}
+		if m.Stats() != c.stats {
+			t.Errorf("%s: Stats = %+v; want %+v", c.name, m.Stats(), c.stats)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	for _, opt := range []Options{
+		{Policy: LFU + 1},
+		{MaxEntries: -1},
+		{MaxBytes: -1},
+		{MaxBytes: 10},
+	} {
+		if _, err := New(opt); err == nil {
+			t.Errorf("New(%+v): err = nil", opt)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package container
+
+import "strings"
+
+var (
+	dotReplacer     = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	mermaidReplacer = strings.NewReplacer(`"`, "#quot;", "\n", "<br/>", "\r", "")
+)
+
+// 转义DOT字符串中的特殊字符，各容器包的WriteDOT用它处理节点的标签
+func DotEscape(s string) string {
+	return dotReplacer.Replace(s)
+}
+
+// 转义Mermaid标签中的特殊字符，各容器包的WriteMermaid用它处理节点的标签
+func MermaidEscape(s string) string {
+	return mermaidReplacer.Replace(s)
+}
+
+// 为n个节点依次编号，node(i)返回第i个节点，返回节点到序号的映射，用于生成图中节点的名称
+func Number(n int, node func(i int) interface{}) map[interface{}]int {
+	m := make(map[interface{}]int, n)
+	for i := 0; i < n; i++ {
+		m[node(i)] = i
+	}
+	return m
+}
@@ -0,0 +1,36 @@
+package container
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	cases := []struct {
+		in, dot, mermaid string
+	}{
+		{"plain", "plain", "plain"},
+		{`a"b`, `a\"b`, "a#quot;b"},
+		{`a\b`, `a\\b`, `a\b`},
+		{"a\r\nb", `a\nb`, "a<br/>b"},
+		{"中文", "中文", "中文"},
+	}
+	for _, c := range cases {
+		if got := DotEscape(c.in); got != c.dot {
+			t.Errorf("DotEscape(%q) = %q; want %q", c.in, got, c.dot)
+		}
+		if got := MermaidEscape(c.in); got != c.mermaid {
+			t.Errorf("MermaidEscape(%q) = %q; want %q", c.in, got, c.mermaid)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	v := []*int{new(int), new(int), new(int)}
+	m := Number(len(v), func(i int) interface{} { return v[i] })
+	if len(m) != len(v) {
+		t.Fatalf("Number returned %d entries; want %d", len(m), len(v))
+	}
+	for i, p := range v {
+		if m[p] != i {
+			t.Errorf("node %d is numbered %d", i, m[p])
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// index包提供带有二级索引的键值表：主表是一棵avl.AVL，二级索引由func(V) Key定义，
+// 在插入、更新和删除时自动维护，查询索引得到的是主表中的键值对
+package index
+
+import (
+	"fmt"
+
+	"github.com/hydra13142/container"
+	"github.com/hydra13142/container/avl"
+)
+
+type typeA = int64
+
+type typeB = string
+
+type typeC = interface{}
+
+// 主键和索引键的类型
+type Key = avl.Key
+
+// 主表中的一个键值对
+type Entry struct {
+	Key Key
+	Val typeC
+}
+
+// 带有二级索引的键值表，主键唯一
+type Map struct {
+	prim *avl.AVL
+	list []*Index
+	name map[string]*Index
+	size int
+}
+
+// 二级索引。索引树的键由索引键和主键复合而成：N为索引键的N，S为索引键的S的保序编码之后接主键的保序编码，
+// 因此索引树按（索引键，主键）的顺序排列，同一索引键的各项可以按编码前缀一并找到
+type Index struct {
+	name   string
+	key    func(typeC) Key
+	unique bool
+	tree   *avl.AVL
+	owner  *Map
+}
+
+// 索引键k的各项在索引树中共有的键前缀
+func prefix(k Key) Key {
+	return Key{N: k.N, S: string(container.AppendString(nil, k.S))}
+}
+
+// 索引键k、主键pk对应的索引树中的键
+func composite(k, pk Key) Key {
+	p := prefix(k)
+	return Key{N: p.N, S: string(pk.AppendEncoded([]byte(p.S)))}
+}
+
+// 键x是否小于y
+func less(x, y Key) bool {
+	return x.N < y.N || x.N == y.N && x.S < y.S
+}
+
+// 创建一个没有二级索引的键值表
+func New() *Map {
+	return &Map{prim: avl.New(), name: map[string]*Index{}}
+}
+
+// 注册名为name的二级索引，key由主表中的值计算索引键，unique为true时不同的键值对不能有相同的索引键。
+// 已有的键值对会被加入索引；名称重复或已有的键值对违反唯一性时返回错误，此时不注册索引
+func (this *Map) AddIndex(name string, key func(typeC) Key, unique bool) (*Index, error) {
+	if _, ok := this.name[name]; ok {
+		return nil, fmt.Errorf("index: 索引%s已存在", name)
+	}
+	x := &Index{name, key, unique, avl.New(), this}
+	for p := this.prim.Min(); p != nil; p = p.Next() {
+		n, s := p.Key()
+		pk, k := Key{N: n, S: s}, key(p.Val())
+		if err := x.vacant(k, pk); err != nil {
+			return nil, err
+		}
+		x.insert(k, pk)
+	}
+	this.list = append(this.list, x)
+	this.name[name] = x
+	return x, nil
+}
+
+// 返回名为name的二级索引，不存在时返回nil
+func (this *Map) Index(name string) *Index {
+	return this.name[name]
+}
+
+// 返回键值对的数目
+func (this *Map) Len() int {
+	return this.size
+}
+
+// 根据主键查找值
+func (this *Map) Get(n typeA, s typeB) (typeC, bool) {
+	if p := this.prim.Search(n, s); p != nil {
+		return p.Val(), true
+	}
+	return nil, false
+}
+
+// 如果主键已存在，更新值；如果不存在，插入新的键值对。各索引随之更新；
+// 新的值在某个唯一索引上与其他键值对冲突时返回错误，此时表和索引都不变
+func (this *Map) Update(n typeA, s typeB, v typeC) error {
+	return this.put(Key{N: n, S: s}, v, true)
+}
+
+// 插入新的键值对，主键已存在或新的值违反唯一索引时返回错误，此时表和索引都不变
+func (this *Map) Insert(n typeA, s typeB, v typeC) error {
+	return this.put(Key{N: n, S: s}, v, false)
+}
+
+// 先检查全部约束再修改，保证出错时没有任何改动
+func (this *Map) put(pk Key, v typeC, replace bool) error {
+	p := this.prim.Search(pk.N, pk.S)
+	if p != nil && !replace {
+		return fmt.Errorf("index: 主键(%d, %s)已存在", pk.N, pk.S)
+	}
+	keys := make([]Key, len(this.list))
+	for i, x := range this.list {
+		keys[i] = x.key(v)
+		if err := x.vacant(keys[i], pk); err != nil {
+			return err
+		}
+	}
+	if p != nil {
+		old := p.Val()
+		for _, x := range this.list {
+			x.remove(x.key(old), pk)
+		}
+		p.Set(v)
+	} else {
+		this.prim.Insert(pk.N, pk.S, v)
+		this.size++
+	}
+	for i, x := range this.list {
+		x.insert(keys[i], pk)
+	}
+	return nil
+}
+
+// 根据主键删除键值对并从各索引中移除，返回被删除的值以及主键是否存在
+func (this *Map) Delete(n typeA, s typeB) (typeC, bool) {
+	v, ok := this.prim.Delete(n, s)
+	if !ok {
+		return nil, false
+	}
+	for _, x := range this.list {
+		x.remove(x.key(v), Key{N: n, S: s})
+	}
+	this.size--
+	return v, true
+}
+
+// 索引的名称
+func (this *Index) Name() string {
+	return this.name
+}
+
+// 索引是否要求索引键唯一
+func (this *Index) Unique() bool {
+	return this.unique
+}
+
+// 唯一索引中索引键k已属于pk以外的主键时返回错误
+func (this *Index) vacant(k, pk Key) error {
+	if !this.unique {
+		return nil
+	}
+	var (
+		o  Key
+		ok bool
+	)
+	p := prefix(k)
+	this.tree.ScanPrefix(p.N, p.S, func(q *avl.Node) bool {
+		o = q.Val().(Key)
+		ok = o != pk
+		return !ok
+	})
+	if ok {
+		return fmt.Errorf("index: 索引%s中的键(%d, %s)已属于主键(%d, %s)", this.name, k.N, k.S, o.N, o.S)
+	}
+	return nil
+}
+
+// 加入索引键k到主键pk的对应
+func (this *Index) insert(k, pk Key) {
+	c := composite(k, pk)
+	this.tree.Update(c.N, c.S, pk)
+}
+
+// 移除索引键k到主键pk的对应
+func (this *Index) remove(k, pk Key) {
+	c := composite(k, pk)
+	this.tree.Delete(c.N, c.S)
+}
+
+// 由索引树的节点得到主表中的键值对
+func (this *Index) entry(q *avl.Node) Entry {
+	pk := q.Val().(Key)
+	v, _ := this.owner.Get(pk.N, pk.S)
+	return Entry{pk, v}
+}
+
+// 返回索引键为(n, s)的主键最小的键值对，没有时ok为false
+func (this *Index) Get(n typeA, s typeB) (e Entry, ok bool) {
+	this.Each(n, s, func(x Entry) bool {
+		e, ok = x, true
+		return false
+	})
+	return e, ok
+}
+
+// 按主键的顺序返回索引键为(n, s)的所有键值对
+func (this *Index) Find(n typeA, s typeB) []Entry {
+	var v []Entry
+	this.Each(n, s, func(x Entry) bool {
+		v = append(v, x)
+		return true
+	})
+	return v
+}
+
+// 按主键的顺序对索引键为(n, s)的每个键值对调用f，f返回false时停止，其间修改本表会引发panic
+func (this *Index) Each(n typeA, s typeB, f func(Entry) bool) {
+	p := prefix(Key{N: n, S: s})
+	this.tree.ScanPrefix(p.N, p.S, func(q *avl.Node) bool {
+		return f(this.entry(q))
+	})
+}
+
+// 按（索引键，主键）的顺序对索引键在[lo, hi)范围内的每个键值对调用f，f返回false时停止，其间修改本表会引发panic
+func (this *Index) Range(lo, hi Key, f func(Entry) bool) {
+	l, h := prefix(lo), prefix(hi)
+	c := this.tree.Cursor()
+	for ok := c.Seek(l.N, l.S); ok; ok = c.Next() {
+		n, s := c.Key()
+		if !less(Key{N: n, S: s}, h) {
+			return
+		}
+		pk := c.Value().(Key)
+		v, _ := this.owner.Get(pk.N, pk.S)
+		if !f(Entry{pk, v}) {
+			return
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package index
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 测试中主表的值，索引键由其字段给出
+type person struct {
+	age  int64
+	city string
+	mail string
+}
+
+func byCity(v typeC) Key {
+	p := v.(person)
+	return Key{N: p.age, S: p.city}
+}
+
+func byMail(v typeC) Key {
+	return Key{S: v.(person).mail}
+}
+
+// 创建带有非唯一索引city和唯一索引mail的表
+func people(t *testing.T) (*Map, *Index, *Index) {
+	m := New()
+	city, err := m.AddIndex("city", byCity, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail, err := m.AddIndex("mail", byMail, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, city, mail
+}
+
+// 按（索引键，主键）的顺序列出索引中的全部键值对
+func all(x *Index) []Entry {
+	var v []Entry
+	x.Range(Key{N: -1 << 63}, Key{N: 1<<63 - 1, S: "\xff\xff"}, func(e Entry) bool {
+		v = append(v, e)
+		return true
+	})
+	return v
+}
+
+func TestUniqueViolation(t *testing.T) {
+	m, city, mail := people(t)
+	m.Insert(1, "", person{30, "rome", "a@x"})
+	m.Insert(2, "", person{40, "oslo", "b@x"})
+	before, cities := all(mail), all(city)
+	for _, c := range []struct {
+		op string
+		n  int64
+		v  person
+	}{
+		{"insert", 3, person{50, "kyiv", "a@x"}},
+		{"insert", 1, person{50, "kyiv", "c@x"}},
+		{"update", 2, person{50, "kyiv", "a@x"}},
+		{"update", 3, person{50, "kyiv", "b@x"}},
+	} {
+		var err error
+		if c.op == "insert" {
+			err = m.Insert(c.n, "", c.v)
+		} else {
+			err = m.Update(c.n, "", c.v)
+		}
+		if err == nil {
+			t.Fatalf("%s(%d, %v) succeeded", c.op, c.n, c.v)
+		}
+		if m.Len() != 2 {
+			t.Fatalf("Len() = %d after failed %s; want 2", m.Len(), c.op)
+		}
+		if got := all(mail); !reflect.DeepEqual(got, before) {
+			t.Fatalf("index mail is %v after failed %s; want %v", got, c.op, before)
+		}
+		if got := all(city); !reflect.DeepEqual(got, cities) {
+			t.Fatalf("index city is %v after failed %s; want %v", got, c.op, cities)
+		}
+		if v, _ := m.Get(2, ""); v != (person{40, "oslo", "b@x"}) {
+			t.Fatalf("Get(2) = %v after failed %s", v, c.op)
+		}
+	}
+	// 键值对更新为自己原有的唯一索引键不算冲突
+	if err := m.Update(1, "", person{31, "rome", "a@x"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateRemovesStaleEntries(t *testing.T) {
+	m, city, mail := people(t)
+	m.Insert(1, "", person{30, "rome", "a@x"})
+	m.Insert(2, "", person{30, "rome", "b@x"})
+	if err := m.Update(1, "", person{35, "oslo", "c@x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := city.Find(30, "rome"); len(got) != 1 || got[0].Key.N != 2 {
+		t.Fatalf("Find(30, rome) = %v; want only key 2", got)
+	}
+	if got := city.Find(35, "oslo"); len(got) != 1 || got[0].Key.N != 1 {
+		t.Fatalf("Find(35, oslo) = %v; want key 1", got)
+	}
+	if _, ok := mail.Get(0, "a@x"); ok {
+		t.Fatal("mail a@x is still indexed after the update")
+	}
+	if got := all(city); len(got) != 2 {
+		t.Fatalf("index city has %d entries; want 2", len(got))
+	}
+	// 旧的唯一索引键已经释放
+	if err := m.Insert(3, "", person{20, "kyiv", "a@x"}); err != nil {
+		t.Fatal(err)
+	}
+	m.Delete(2, "")
+	if got := city.Find(30, "rome"); len(got) != 0 {
+		t.Fatalf("Find(30, rome) = %v after Delete; want nothing", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	cities := []string{"", "a", "a\x00", "a\x00b", "ab", "b"}
+	m, city, _ := people(t)
+	type row struct {
+		k, pk Key
+	}
+	var ref []row
+	for i := 0; i < 300; i++ {
+		pk := Key{N: rd.Int63n(4), S: fmt.Sprint(i)}
+		v := person{rd.Int63n(3), cities[rd.Intn(len(cities))], fmt.Sprint(i)}
+		if err := m.Insert(pk.N, pk.S, v); err != nil {
+			t.Fatal(err)
+		}
+		ref = append(ref, row{byCity(v), pk})
+	}
+	sort.Slice(ref, func(i, j int) bool {
+		if a, b := ref[i].k, ref[j].k; a != b {
+			return less(a, b)
+		}
+		return less(ref[i].pk, ref[j].pk)
+	})
+	for it := 0; it < 500; it++ {
+		lo := Key{N: rd.Int63n(4) - 1, S: cities[rd.Intn(len(cities))]}
+		hi := Key{N: rd.Int63n(4) - 1, S: cities[rd.Intn(len(cities))]}
+		var want []Key
+		for _, r := range ref {
+			if !less(r.k, lo) && less(r.k, hi) {
+				want = append(want, r.pk)
+			}
+		}
+		var got []Key
+		city.Range(lo, hi, func(e Entry) bool {
+			got = append(got, e.Key)
+			if k := byCity(e.Val); less(k, lo) || !less(k, hi) {
+				t.Fatalf("Range(%v, %v) yielded %v", lo, hi, e)
+			}
+			return true
+		})
+		if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+			t.Fatalf("Range(%q, %q) = %v; want %v", lo, hi, got, want)
+		}
+	}
+}
+
+func TestRangeModifyPanics(t *testing.T) {
+	m, city, _ := people(t)
+	for i := int64(0); i < 4; i++ {
+		m.Insert(i, "", person{i, "rome", fmt.Sprint(i)})
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("modifying the map during Range did not panic")
+		}
+	}()
+	city.Range(Key{N: 0}, Key{N: 9}, func(e Entry) bool {
+		m.Update(e.Key.N+10, "", person{7, "oslo", fmt.Sprint(e.Key.N + 10)})
+		return true
+	})
+}
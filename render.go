@@ -0,0 +1,125 @@
+package container
+
+import (
+	"bufio"
+	"io"
+)
+
+// 以文本格式流式显示二叉树时的选项，零值即为ASCII样式、"\n"换行
+type RenderOptions struct {
+	Unicode  bool   // 使用Unicode制表符代替ASCII字符绘制连线
+	Newline  string // 换行符，为空时使用"\n"
+	MaxDepth int    // 显示的最大深度（根节点深度为1），更深的子树以"…"代替，0表示不限制
+	MaxWidth int    // 标签的最大显示宽度，超出时截断并以"…"结尾，0表示不限制
+}
+
+// 绘制连线用的各种字符串
+type glyphs struct {
+	root [3]string // 根节点所在行之前、所在行、之后的前缀
+	lson [3]string // 左子树中左子节点所在行之前、所在行、之后的前缀
+	rson [3]string // 右子树中右子节点所在行之前、所在行、之后的前缀
+	bar  string    // 父子节点之间的竖线
+}
+
+var (
+	asciiGlyphs   = glyphs{[3]string{"    ", "----", "    "}, [3]string{"     ", "+----", "|    "}, [3]string{"|    ", "+----", "     "}, "|"}
+	unicodeGlyphs = glyphs{[3]string{"    ", "────", "    "}, [3]string{"     ", "┌────", "│    "}, [3]string{"│    ", "└────", "     "}, "│"}
+)
+
+// 流式输出二叉树所需的状态，前缀随递归深度增减，因此内存占用只与树高有关
+type renderer struct {
+	w     *bufio.Writer
+	opt   *RenderOptions
+	g     *glyphs
+	pre   []byte
+	child func(interface{}) (interface{}, interface{})
+	label func(interface{}) string
+}
+
+// 字符的显示宽度，中日韩文字及全角符号等宽字符计为2，其余计为1
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF && r != 0x303F,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
+
+// 将标签截断到不超过n个显示宽度，被截断时以"…"结尾
+func truncate(s string, n int) string {
+	if n <= 0 || len(s) <= n/2 {
+		return s
+	}
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	if w <= n {
+		return s
+	}
+	w = 0
+	for i, r := range s {
+		if w+runeWidth(r) > n-1 {
+			return s[:i] + "…"
+		}
+		w += runeWidth(r)
+	}
+	return s
+}
+
+// 输出一行：当前前缀、本行的附加前缀和内容
+func (this *renderer) line(seg, s string) {
+	this.w.Write(this.pre)
+	this.w.WriteString(seg)
+	this.w.WriteString(s)
+	this.w.WriteString(this.opt.Newline)
+}
+
+// 输出以p为根的子树，seg为父节点给该子树的根节点所在行之前、所在行、之后各行附加的前缀
+func (this *renderer) node(p interface{}, seg *[3]string, depth int) {
+	if this.opt.MaxDepth > 0 && depth > this.opt.MaxDepth {
+		this.line(seg[1], "…")
+		return
+	}
+	n := len(this.pre)
+	l, r := this.child(p)
+	if l != nil {
+		this.pre = append(this.pre, seg[0]...)
+		this.node(l, &this.g.lson, depth+1)
+		this.pre = this.pre[:n]
+		this.line(seg[0], this.g.bar)
+	}
+	this.line(seg[1], truncate(this.label(p), this.opt.MaxWidth))
+	if r != nil {
+		this.line(seg[2], this.g.bar)
+		this.pre = append(this.pre, seg[2]...)
+		this.node(r, &this.g.rson, depth+1)
+		this.pre = this.pre[:n]
+	}
+}
+
+// 将以root为根的二叉树以文本格式流式地写入w，左子树在上、右子树在下，不在内存中生成整幅图。
+// child返回节点的左右子节点，没有时为nil；label生成节点的标签；root为nil时表示空树。
+// 供avl、sbt等子包的Render使用
+func Render(w io.Writer, root interface{}, child func(interface{}) (interface{}, interface{}), label func(interface{}) string, opts RenderOptions) error {
+	if opts.Newline == "" {
+		opts.Newline = "\n"
+	}
+	rd := &renderer{w: bufio.NewWriter(w), opt: &opts, g: &asciiGlyphs, child: child, label: label}
+	if opts.Unicode {
+		rd.g = &unicodeGlyphs
+	}
+	if root != nil {
+		rd.node(root, &rd.g.root, 1)
+	}
+	return rd.w.Flush()
+}
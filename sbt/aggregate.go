@@ -0,0 +1,137 @@
+package sbt
+
+// 聚合运算：combine须满足结合律，lift将节点的值映射为聚合值
+type monoid struct {
+	combine func(a, b typeD) typeD
+	lift    func(v typeC) typeD
+}
+
+// 创建一个维护聚合值的SBT树，每个节点缓存其子树中所有值按键的顺序用combine合并的结果。
+// combine须满足结合律（不要求交换律），如求和、最小值、最大值等；lift将单个值映射为聚合值。
+func NewAggregated(combine func(a, b typeD) typeD, lift func(v typeC) typeD) *SBT {
+	p := New()
+	p.aux = &monoid{combine, lift}
+	return p
+}
+
+// 由子节点的聚合值重新计算节点的聚合值，非聚合树中什么也不做
+func (this *SBT) pull(p *Node) {
+	if this.aux == nil {
+		return
+	}
+	v := this.aux.lift(p.item.Val)
+	if l := p.Lson(); l != null {
+		v = this.aux.combine(l.agg, v)
+	}
+	if r := p.Rson(); r != null {
+		v = this.aux.combine(v, r.agg)
+	}
+	p.agg = v
+}
+
+// 节点的值改变后，沿父节点指针重新计算到根节点为止的聚合值
+func (this *SBT) repair(p *Node) {
+	if this.aux == nil {
+		return
+	}
+	for ; p != nil; p = p.ptO {
+		this.pull(p)
+	}
+}
+
+// 按后序重新计算整棵子树的聚合值，用于结构被整体重建之后
+func (this *SBT) refresh(p *Node) {
+	if this.aux == nil || p == null {
+		return
+	}
+	this.refresh(p.Lson())
+	this.refresh(p.Rson())
+	this.pull(p)
+}
+
+// 设置树中节点的值，并更新其各祖先节点的聚合值
+func (this *SBT) SetVal(p *Node, v typeC) {
+	if debug {
+		defer this.check()
+	}
+	p.item.Val = v
+	this.repair(p)
+}
+
+// 子树中键不小于lo的节点的聚合值，ok为false表示没有这样的节点
+func (this *SBT) suffix(p *Node, lo *Key) (s typeD, ok bool) {
+	for p != null {
+		if compare(&p.item.Key, lo) < 0 {
+			p = p.Rson()
+			continue
+		}
+		v := this.aux.lift(p.item.Val)
+		if r := p.Rson(); r != null {
+			v = this.aux.combine(v, r.agg)
+		}
+		if ok {
+			s = this.aux.combine(v, s)
+		} else {
+			s, ok = v, true
+		}
+		p = p.Lson()
+	}
+	return s, ok
+}
+
+// 子树中键小于hi的节点的聚合值，ok为false表示没有这样的节点
+func (this *SBT) prefix(p *Node, hi *Key) (s typeD, ok bool) {
+	for p != null {
+		if compare(&p.item.Key, hi) >= 0 {
+			p = p.Lson()
+			continue
+		}
+		v := this.aux.lift(p.item.Val)
+		if l := p.Lson(); l != null {
+			v = this.aux.combine(l.agg, v)
+		}
+		if ok {
+			s = this.aux.combine(s, v)
+		} else {
+			s, ok = v, true
+		}
+		p = p.Rson()
+	}
+	return s, ok
+}
+
+// 返回键在[lo, hi)范围内所有值按键的顺序合并的聚合值，耗时O(log n)。
+// 范围内没有键值对，或者本树不是由NewAggregated创建的，ok为false。
+func (this *SBT) Aggregate(lo, hi Key) (v typeD, ok bool) {
+	if this.aux == nil {
+		return nil, false
+	}
+	// 找到第一个落在范围内的节点，范围内的其余节点都在其两棵子树中
+	p := this.root
+	for p != null {
+		switch {
+		case compare(&p.item.Key, &lo) < 0:
+			p = p.Rson()
+		case compare(&p.item.Key, &hi) >= 0:
+			p = p.Lson()
+		default:
+			v = this.aux.lift(p.item.Val)
+			if s, ok := this.suffix(p.Lson(), &lo); ok {
+				v = this.aux.combine(s, v)
+			}
+			if s, ok := this.prefix(p.Rson(), &hi); ok {
+				v = this.aux.combine(v, s)
+			}
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// 返回整棵树所有值的聚合值，耗时O(1)；树为空或不是聚合树时ok为false
+func (this *SBT) Total() (typeD, bool) {
+	if this.aux == nil || this.root == null {
+		return nil, false
+	}
+	return this.root.agg, true
+}
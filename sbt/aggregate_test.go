@@ -0,0 +1,34 @@
+package sbt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCloneAggregated(t *testing.T) {
+	sum := func(a, b typeD) typeD { return a.(int) + b.(int) }
+	lift := func(v typeC) typeD { return v.(int) }
+	rd := rand.New(rand.NewSource(1))
+	tr := NewAggregated(sum, lift)
+	want := 0
+	for i := 0; i < 500; i++ {
+		v := rd.Intn(100)
+		tr.Insert(rd.Int63n(1000), "", v)
+		want += v
+	}
+	c := tr.Clone(func(v typeC) typeC { return v.(int) * 2 })
+	if err := c.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := c.Aggregate(Key{0, ""}, Key{1000, ""}); s != 2*want {
+		t.Fatalf("Aggregate of the clone = %v; want %d", s, 2*want)
+	}
+	if s, _ := tr.Aggregate(Key{0, ""}, Key{1000, ""}); s != want {
+		t.Fatalf("Aggregate of the original = %v; want %d", s, want)
+	}
+	// 绕过SBT.SetVal直接修改值，聚合值不再正确，Verify应当发现
+	c.Min().Set(-1)
+	if err := c.Verify(); err == nil {
+		t.Fatal("Verify did not notice a stale aggregate")
+	}
+}
@@ -0,0 +1,112 @@
+package sbt
+
+// 可重集合，每个键只占一个节点，节点的值为该键出现的次数（uint）。
+// 建立在聚合SBT之上，节点的聚合值为子树中各键出现次数之和，按序号和排名的查询都计入重数
+type Bag struct {
+	tree *SBT
+}
+
+// 子树中各键出现次数之和
+func weight(p *Node) uint {
+	if p == null {
+		return 0
+	}
+	return p.agg.(uint)
+}
+
+// 创建一个可重集合
+func NewBag() *Bag {
+	t := NewAggregated(
+		func(a, b typeD) typeD { return a.(uint) + b.(uint) },
+		func(v typeC) typeD { return v.(uint) })
+	return &Bag{t}
+}
+
+// 将键(n, s)加入times次，返回加入后该键出现的次数
+func (this *Bag) Add(n typeA, s typeB, times uint) uint {
+	v, ok := this.tree.Compute(n, s, func(old typeC, ok bool) (typeC, bool) {
+		if ok {
+			times += old.(uint)
+		}
+		return times, times > 0
+	})
+	if !ok {
+		return 0
+	}
+	return v.(uint)
+}
+
+// 将键(n, s)移除times次，次数不足时全部移除，返回移除后该键出现的次数
+func (this *Bag) Remove(n typeA, s typeB, times uint) uint {
+	v, ok := this.tree.Compute(n, s, func(old typeC, ok bool) (typeC, bool) {
+		if !ok || old.(uint) <= times {
+			return nil, false
+		}
+		return old.(uint) - times, true
+	})
+	if !ok {
+		return 0
+	}
+	return v.(uint)
+}
+
+// 返回键(n, s)出现的次数
+func (this *Bag) Count(n typeA, s typeB) uint {
+	if p := this.tree.Search(n, s); p != nil {
+		return p.item.Val.(uint)
+	}
+	return 0
+}
+
+// 返回所有键出现次数的总和，耗时O(1)
+func (this *Bag) Len() uint {
+	return weight(this.tree.root)
+}
+
+// 返回不同的键的数目
+func (this *Bag) Distinct() uint {
+	return this.tree.root.cnt
+}
+
+// 将所有的键按升序逐个重复排列，返回其中第i个（从0开始）键，i超出范围时ok为false。耗时O(log n)
+func (this *Bag) Index(i uint) (k Key, ok bool) {
+	for p := this.tree.root; p != null; {
+		l := weight(p.Lson())
+		c := p.item.Val.(uint)
+		switch {
+		case i < l:
+			p = p.Lson()
+		case i < l+c:
+			return p.item.Key, true
+		default:
+			i -= l + c
+			p = p.Rson()
+		}
+	}
+	return Key{}, false
+}
+
+// 返回小于键(n, s)的键出现次数的总和，耗时O(log n)
+func (this *Bag) Rank(n typeA, s typeB) uint {
+	var r uint
+	k := &Key{n, s}
+	for p := this.tree.root; p != null; {
+		if compare(&p.item.Key, k) < 0 {
+			r += weight(p.Lson()) + p.item.Val.(uint)
+			p = p.Rson()
+		} else {
+			p = p.Lson()
+		}
+	}
+	return r
+}
+
+// 按升序对每个不同的键及其出现次数调用f，f返回false时停止，其间修改本集合会引发panic
+func (this *Bag) Each(f func(k Key, times uint) bool) {
+	g := this.tree.guard(func(p *Node) bool { return f(p.item.Key, p.item.Val.(uint)) })
+	for p := this.tree.Min(); p != nil; p = p.Next() {
+		if !g(p) {
+			return
+		}
+	}
+}
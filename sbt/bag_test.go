@@ -0,0 +1,77 @@
+package sbt
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// 以各键出现次数的映射作为模型检查Bag，Index和Rank与把每个键按次数重复展开后的有序序列比较
+func TestBag(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	b := NewBag()
+	ref := map[Key]uint{}
+	key := func() Key {
+		return Key{rd.Int63n(30), string(rune('a' + rd.Intn(2)))}
+	}
+	for it := 0; it < 3000; it++ {
+		k, times := key(), uint(rd.Intn(4))
+		if rd.Intn(3) > 0 {
+			ref[k] += times
+			if got := b.Add(k.N, k.S, times); got != ref[k] {
+				t.Fatalf("Add(%v, %d) = %d; want %d", k, times, got, ref[k])
+			}
+		} else {
+			if ref[k] > times {
+				ref[k] -= times
+			} else {
+				ref[k] = 0
+			}
+			if got := b.Remove(k.N, k.S, times); got != ref[k] {
+				t.Fatalf("Remove(%v, %d) = %d; want %d", k, times, got, ref[k])
+			}
+		}
+		if ref[k] == 0 {
+			delete(ref, k)
+		}
+		if err := b.tree.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		var keys, all []Key
+		for x := range ref {
+			keys = append(keys, x)
+		}
+		sort.Slice(keys, func(i, j int) bool { return compare(&keys[i], &keys[j]) < 0 })
+		for _, x := range keys {
+			for i := uint(0); i < ref[x]; i++ {
+				all = append(all, x)
+			}
+		}
+		if b.Len() != uint(len(all)) || b.Distinct() != uint(len(keys)) {
+			t.Fatalf("Len, Distinct = %d, %d; want %d, %d", b.Len(), b.Distinct(), len(all), len(keys))
+		}
+		q := key()
+		if c := b.Count(q.N, q.S); c != ref[q] {
+			t.Fatalf("Count(%v) = %d; want %d", q, c, ref[q])
+		}
+		r := sort.Search(len(all), func(i int) bool { return compare(&all[i], &q) >= 0 })
+		if got := b.Rank(q.N, q.S); got != uint(r) {
+			t.Fatalf("Rank(%v) = %d; want %d", q, got, r)
+		}
+		i := uint(rd.Intn(len(all) + 2))
+		if x, ok := b.Index(i); ok != (i < uint(len(all))) || ok && x != all[i] {
+			t.Fatalf("Index(%d) = %v, %v with %d keys", i, x, ok, len(all))
+		}
+		j := 0
+		b.Each(func(x Key, times uint) bool {
+			if j >= len(keys) || x != keys[j] || times != ref[x] {
+				t.Fatalf("Each yielded %v %d times at position %d", x, times, j)
+			}
+			j++
+			return true
+		})
+		if j != len(keys) {
+			t.Fatalf("Each yielded %d keys; want %d", j, len(keys))
+		}
+	}
+}
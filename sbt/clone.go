@@ -0,0 +1,77 @@
+package sbt
+
+// 复制以p为根的子树并按中序将新节点追加到v中，f不为nil时用于复制值；新节点的线索由thread设置
+func clone(p *Node, f func(typeC) typeC, v *[]*Node) *Node {
+	if p == null {
+		return null
+	}
+	q := p.copy()
+	if f != nil {
+		q.item.Val = f(p.item.Val)
+	}
+	l := clone(p.Lson(), f, v)
+	*v = append(*v, q)
+	r := clone(p.Rson(), f, v)
+	if l != null {
+		q.ptA, l.ptO = l, q
+	}
+	if r != null {
+		q.ptB, r.ptO = r, q
+	}
+	return q
+}
+
+// 按中序序列为没有子节点的一侧设置前驱、后继线索
+func thread(v []*Node) {
+	for i, p := range v {
+		if p.mrk&2 == 0 {
+			if p.ptA = nil; i > 0 {
+				p.ptA = v[i-1]
+			}
+		}
+		if p.mrk&1 == 0 {
+			if p.ptB = nil; i+1 < len(v) {
+				p.ptB = v[i+1]
+			}
+		}
+	}
+}
+
+// 在O(n)时间内复制整棵树，保持原有的形状、大小与线索，聚合树的聚合运算也一并沿用；
+// f不为nil时用于复制值，此时聚合树的聚合值会由新的值重新计算，否则新树与原树共享值
+func (this *SBT) Clone(f func(typeC) typeC) *SBT {
+	var v []*Node
+	p := New()
+	p.aux = this.aux
+	if p.root = clone(this.root, f, &v); p.root != null {
+		p.root.ptO = nil
+	}
+	thread(v)
+	if f != nil && p.aux != nil {
+		p.refresh(p.root)
+	}
+	return p
+}
+
+// 删除全部键值对
+func (this *SBT) Clear() {
+	this.root = null
+	this.mod++
+}
+
+// 判断两棵树是否按键的顺序包含相同的键值对，与树的形状无关；eq为nil时只比较键
+func (this *SBT) Equal(other *SBT, eq func(a, b typeC) bool) bool {
+	if this.root.cnt != other.root.cnt {
+		return false
+	}
+	p, q := this.Min(), other.Min()
+	for ; p != nil && q != nil; p, q = p.Next(), q.Next() {
+		if compare(&p.item.Key, &q.item.Key) != 0 {
+			return false
+		}
+		if eq != nil && !eq(p.item.Val, q.item.Val) {
+			return false
+		}
+	}
+	return p == nil && q == nil
+}
@@ -0,0 +1,108 @@
+package sbt
+
+// 返回第一个键不小于k的节点，没有时返回nil
+func (this *SBT) ceil(k *Key) *Node {
+	var q *Node
+	for p := this.root; p != null; {
+		if compare(&p.item.Key, k) >= 0 {
+			q, p = p, p.Lson()
+		} else {
+			p = p.Rson()
+		}
+	}
+	return q
+}
+
+// SBT树的游标，指向树中的一个节点，或者处于无效位置
+type Cursor struct {
+	tree *SBT
+	node *Node
+	mod  uint64 // 定位时容器的修改次数
+}
+
+// 创建本树的游标，初始处于无效位置
+func (this *SBT) Cursor() *Cursor {
+	return &Cursor{tree: this}
+}
+
+// 移动到第一个键不小于(n, s)的节点，返回游标是否有效
+func (this *Cursor) Seek(n typeA, s typeB) bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.ceil(&Key{n, s})
+	return this.node != nil
+}
+
+// 移动到最小键的节点，返回游标是否有效
+func (this *Cursor) SeekFirst() bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.Min()
+	return this.node != nil
+}
+
+// 移动到最大键的节点，返回游标是否有效
+func (this *Cursor) SeekLast() bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.Max()
+	return this.node != nil
+}
+
+// 移动到后继节点，返回游标是否有效
+func (this *Cursor) Next() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = this.node.Next()
+	}
+	return this.node != nil
+}
+
+// 移动到前驱节点，返回游标是否有效
+func (this *Cursor) Prev() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = this.node.Prev()
+	}
+	return this.node != nil
+}
+
+// 游标是否指向树中的节点
+func (this *Cursor) Valid() bool {
+	return this.node != nil
+}
+
+// 返回当前节点的键，游标无效时panic
+func (this *Cursor) Key() (typeA, typeB) {
+	this.sync()
+	return this.node.Key()
+}
+
+// 返回当前节点的值，游标无效时panic
+func (this *Cursor) Value() typeC {
+	this.sync()
+	return this.node.Val()
+}
+
+// 设置当前节点的值，聚合树中会同时更新聚合值，游标无效时panic
+func (this *Cursor) SetValue(v typeC) {
+	this.sync()
+	this.tree.SetVal(this.node, v)
+}
+
+// 删除当前节点并移动到其后继节点，返回游标是否有效；游标无效时什么也不做
+func (this *Cursor) Delete() bool {
+	p := this.node
+	if p == nil {
+		return false
+	}
+	this.sync()
+	this.node = p.Next()
+	this.tree.DeleteNode(p)
+	this.mod = this.tree.mod
+	return this.node != nil
+}
+
+// 确认游标定位以来容器没有被游标以外的途径插入或删除过节点，否则panic
+func (this *Cursor) sync() {
+	if this.mod != this.tree.mod {
+		panic(ErrModified)
+	}
+}
@@ -0,0 +1,59 @@
+package sbt
+
+import (
+	"fmt"
+
+	"github.com/hydra13142/container"
+)
+
+// 将键的保序编码追加到dst中并返回，编码方式见container.AppendKey。
+// 两个键编码后用bytes.Compare比较的结果与compare相同
+func (this Key) AppendEncoded(dst []byte) []byte {
+	return container.AppendKey(dst, this.N, this.S)
+}
+
+// 由AppendEncoded的结果还原键，编码无效或有多余的字节时返回错误
+func DecodeKey(b []byte) (Key, error) {
+	n, s, err := container.ReadKey(b)
+	return Key{n, s}, err
+}
+
+// 同FromSorted，但键以保序编码给出，因此可以直接使用按bytes.Compare排序的编码序列。
+// 编码直接解码到待构建的键值对中，不经过中间的键序列
+func FromEncoded(keys [][]byte, vals []typeC) (*SBT, error) {
+	if vals != nil && len(vals) != len(keys) {
+		return nil, fmt.Errorf("sbt: 键的数目%d与值的数目%d不一致", len(keys), len(vals))
+	}
+	list := newEntries(len(keys))
+	for i, b := range keys {
+		n, s, err := container.ReadKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("sbt: 第%d个键的编码无效：%v", i, err)
+		}
+		list[i].item.Key = Key{n, s}
+		if vals != nil {
+			list[i].item.Val = vals[i]
+		}
+	}
+	return fromNodes(list)
+}
+
+// 同FromSortedFunc，但键以保序编码给出
+func FromEncodedFunc(next func() (k []byte, v typeC, ok bool)) (*SBT, error) {
+	var (
+		keys []Key
+		vals []typeC
+	)
+	for {
+		b, x, ok := next()
+		if !ok {
+			break
+		}
+		n, s, err := container.ReadKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("sbt: 第%d个键的编码无效：%v", len(keys), err)
+		}
+		keys, vals = append(keys, Key{n, s}), append(vals, x)
+	}
+	return FromSorted(keys, vals)
+}
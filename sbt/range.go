@@ -0,0 +1,144 @@
+package sbt
+
+// 将l、m、r合并为一棵SBT树并返回根节点，要求l的键均不大于m，r的键均不小于m。
+// 若m作根会破坏平衡，则沿较大一侧的边缘下降，挂上m后逐层旋转维护。
+// 调用者应保证l的最大节点的后继线索、r的最小节点的前驱线索均已指向m；
+// 返回的根节点的父节点、整棵树最左和最右两个节点朝外的线索由调用者处理。
+func (this *SBT) join(l, m, r *Node) *Node {
+	switch {
+	case l.Lson().cnt > r.cnt || l.Rson().cnt > r.cnt:
+		t := l.Rson()
+		x := this.join(t, m, r)
+		if t == null {
+			m.ptA = l
+		}
+		l.ptB, l.mrk, x.ptO = x, l.mrk|1, l
+		return this.balance(l)
+	case r.Lson().cnt > l.cnt || r.Rson().cnt > l.cnt:
+		t := r.Lson()
+		x := this.join(l, m, t)
+		if t == null {
+			m.ptB = r
+		}
+		r.ptA, r.mrk, x.ptO = x, r.mrk|2, r
+		return this.balance(r)
+	}
+	m.mrk = 0
+	if l != null {
+		m.ptA, m.mrk, l.ptO = l, m.mrk|2, m
+	}
+	if r != null {
+		m.ptB, m.mrk, r.ptO = r, m.mrk|1, m
+	}
+	m.cnt = l.cnt + r.cnt + 1
+	this.pull(m)
+	return m
+}
+
+// 将以t为根的树分割为键小于k和键不小于k的两棵树。
+// 分割后两棵树内部的中序相邻关系都与原树相同，因此线索只有两端需要修正。
+func (this *SBT) split(t *Node, k *Key) (*Node, *Node) {
+	if t == null {
+		return null, null
+	}
+	l, r := t.Lson(), t.Rson()
+	if compare(&t.item.Key, k) < 0 {
+		a, b := this.split(r, k)
+		return this.join(l, t, a), b
+	}
+	a, b := this.split(l, k)
+	return a, this.join(b, t, r)
+}
+
+// 移除树中最小的节点，返回剩余部分的根节点和被移除的节点
+func (this *SBT) popMin(t *Node) (*Node, *Node) {
+	l := t.Lson()
+	if l == null {
+		return t.Rson(), t
+	}
+	x, m := this.popMin(l)
+	if x == null {
+		t.ptA, t.mrk = m, t.mrk&1
+	} else {
+		t.ptA, x.ptO = x, t
+	}
+	return this.balance(t), m
+}
+
+// 合并两棵树，要求l的键均不大于r的键
+func (this *SBT) join2(l, r *Node) *Node {
+	if l == null {
+		return r
+	}
+	if r == null {
+		return l
+	}
+	r, m := this.popMin(r)
+	p := l
+	for p.mrk&1 != 0 {
+		p = p.ptB
+	}
+	p.ptB = m
+	return this.join(l, m, r)
+}
+
+// 将整棵树的根节点的父节点、最左节点的前驱线索和最右节点的后继线索置为nil
+func seal(t *Node) {
+	if t == null {
+		return
+	}
+	t.ptO = nil
+	p := t
+	for p.mrk&2 != 0 {
+		p = p.ptA
+	}
+	p.ptA = nil
+	for p = t; p.mrk&1 != 0; p = p.ptB {
+	}
+	p.ptB = nil
+}
+
+// 删除键在[lo, hi)范围内的所有键值对，返回删除的数目。
+// 通过两次分割和一次合并完成，删除的数目直接由子树大小得到。
+func (this *SBT) DeleteRange(lo, hi Key) int {
+	if debug {
+		defer this.check()
+	}
+	if compare(&lo, &hi) >= 0 {
+		return 0
+	}
+	l, x := this.split(this.root, &lo)
+	m, r := this.split(x, &hi)
+	this.root = this.join2(l, r)
+	seal(this.root)
+	if m.cnt > 0 {
+		this.mod++
+	}
+	return int(m.cnt)
+}
+
+// 删除所有使f返回true的键值对，返回删除的数目。
+// f按键的升序被调用，其间修改本树会引发panic；剩余的节点会在O(n)时间内重建为平衡树。
+func (this *SBT) DeleteFunc(f func(*Node) bool) int {
+	if debug {
+		defer this.check()
+	}
+	var v []*Node
+	n := 0
+	f = this.guard(f)
+	for p := this.Min(); p != nil; p = p.Next() {
+		if f(p) {
+			n++
+		} else {
+			v = append(v, p)
+		}
+	}
+	if n > 0 {
+		if this.root = build(v, 0, len(v)); this.root != null {
+			this.root.ptO = nil
+		}
+		this.refresh(this.root)
+		this.mod++
+	}
+	return n
+}
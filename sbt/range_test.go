@@ -0,0 +1,93 @@
+package sbt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// 按序号逐个检查树中的键与升序的ref一致，并检查Rank与Index互逆
+func ranks(t *testing.T, tr *SBT, ref []Key) {
+	t.Helper()
+	if err := tr.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	for i, k := range ref {
+		p := tr.Index(uint(i))
+		if p == nil || p.item.Key != k {
+			t.Fatalf("Index(%d) = %v; want %v", i, p, k)
+		}
+		if r := tr.Rank(k.N, k.S); r != uint(i) {
+			t.Fatalf("Rank(%v) = %d; want %d", k, r, i)
+		}
+	}
+	if p := tr.Index(uint(len(ref))); p != nil {
+		t.Fatalf("Index(%d) = %v; want nil", len(ref), p.item.Key)
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	for it := 0; it < 100; it++ {
+		tr := New()
+		var ref []Key
+		for i := 0; i < 300; i++ {
+			k := Key{int64(i * 2), ""}
+			tr.Update(k.N, k.S, nil)
+			ref = append(ref, k)
+		}
+		for len(ref) > 0 {
+			lo := Key{rd.Int63n(640) - 20, ""}
+			hi := Key{lo.N + rd.Int63n(80), ""}
+			// 键在[lo, hi)中的节点的序号恰好构成区间[Rank(lo), Rank(hi))
+			a, b := tr.Rank(lo.N, lo.S), tr.Rank(hi.N, hi.S)
+			if n := tr.DeleteRange(lo, hi); n != int(b-a) {
+				t.Fatalf("DeleteRange(%v, %v) = %d; want %d", lo, hi, n, b-a)
+			}
+			ref = append(ref[:a], ref[b:]...)
+			ranks(t, tr, ref)
+		}
+		if tr.DeleteRange(Key{-100, ""}, Key{1000, ""}) != 0 || tr.Min() != nil {
+			t.Fatal("DeleteRange on an empty tree changed it")
+		}
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	sum := func(a, b typeD) typeD { return a.(int) + b.(int) }
+	lift := func(v typeC) typeD { return v.(int) }
+	cases := []struct {
+		name string
+		f    func(i int) bool // i为键值对的序号，也是其值
+	}{
+		{"none", func(i int) bool { return false }},
+		{"all", func(i int) bool { return true }},
+		{"first", func(i int) bool { return i == 0 }},
+		{"last", func(i int) bool { return i == 99 }},
+		{"every third", func(i int) bool { return i%3 == 0 }},
+		{"middle", func(i int) bool { return i >= 40 && i < 60 }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := NewAggregated(sum, lift)
+			for i := 0; i < 100; i++ {
+				tr.Insert(int64(i), "", i)
+			}
+			var ref []Key
+			total := 0
+			for i := 0; i < 100; i++ {
+				if !c.f(i) {
+					ref = append(ref, Key{int64(i), ""})
+					total += i
+				}
+			}
+			n := tr.DeleteFunc(func(p *Node) bool { return c.f(p.Val().(int)) })
+			if n != 100-len(ref) {
+				t.Fatalf("DeleteFunc = %d; want %d", n, 100-len(ref))
+			}
+			ranks(t, tr, ref)
+			if s, ok := tr.Total(); ok != (len(ref) > 0) || ok && s != total {
+				t.Fatalf("Total() = %v, %v; want %d", s, ok, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !containerdebug
+
+package sbt
+
+// 未使用containerdebug构建标签时关闭调试模式
+const debug = false
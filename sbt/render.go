@@ -0,0 +1,40 @@
+package sbt
+
+import (
+	"io"
+
+	"github.com/hydra13142/container"
+)
+
+// 以文本格式显示二叉树时的选项，零值即为与Show相同的ASCII样式、"\n"换行
+type RenderOptions struct {
+	Label    func(*Node) string // 生成节点的标签，为nil时使用键
+	Unicode  bool               // 使用Unicode制表符代替ASCII字符绘制连线
+	Newline  string             // 换行符，为空时使用"\n"
+	MaxDepth int                // 显示的最大深度（根节点深度为1），更深的子树以"…"代替，0表示不限制
+	MaxWidth int                // 标签的最大显示宽度，超出时截断并以"…"结尾，0表示不限制
+}
+
+// 将二叉树以文本格式流式地写入w，版式与Show相同（不支持旋转），但不在内存中生成整幅图。
+func (this *SBT) Render(w io.Writer, opts RenderOptions) error {
+	label := opts.Label
+	if label == nil {
+		label = keyLabel
+	}
+	var root interface{}
+	if this.root != null {
+		root = this.root
+	}
+	child := func(x interface{}) (l, r interface{}) {
+		p := x.(*Node)
+		if q := p.Lson(); q != null {
+			l = q
+		}
+		if q := p.Rson(); q != null {
+			r = q
+		}
+		return
+	}
+	return container.Render(w, root, child, func(x interface{}) string { return label(x.(*Node)) },
+		container.RenderOptions{Unicode: opts.Unicode, Newline: opts.Newline, MaxDepth: opts.MaxDepth, MaxWidth: opts.MaxWidth})
+}
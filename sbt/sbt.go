@@ -1,21 +1,25 @@
 package sbt
 
+import "strings"
+
 type typeA = int64
 
 type typeB = string
 
 type typeC = interface{}
 
+type typeD = interface{}
+
 // 由typeA和typeB复合构成的键
 type Key struct {
 	N typeA
 	S typeB
 }
 
-// 二叉树的指针
+// 节点中的键。映射的节点是entry的一部分，entry指向所在的entry；集合的节点没有值，entry为nil
 type item struct {
 	Key
-	Val typeC
+	*entry
 }
 
 // SBT树的节点
@@ -28,13 +32,23 @@ type Node struct {
 	item
 }
 
+// 映射中保存键值对的单元，agg为聚合树中子树的聚合值；平衡代码只使用其中的Node，因此集合可以使用只有键的节点
+type entry struct {
+	Node
+	Val typeC
+	agg typeD
+}
+
 // SBT树
 type SBT struct {
 	root *Node
+	rot  rotation
+	aux  *monoid
+	mod  uint64 // 插入或删除节点的次数，用于发现迭代期间的修改
 }
 
 // 简化代码用的，用来代替空节点的节点
-var null = &Node{}
+var null = new(entry).bind()
 
 // 键值的比较函数
 func compare(x, y *Key) int8 {
@@ -53,7 +67,7 @@ func compare(x, y *Key) int8 {
 	return 0
 }
 
-// 获得前驱节点，用于简单迭代
+// 获得前驱节点，用于简单迭代；不会检查迭代期间的修改（见ErrModified）
 func (this *Node) Prev() *Node {
 	p := this.ptA
 	if this.mrk&2 != 0 {
@@ -63,7 +77,7 @@ func (this *Node) Prev() *Node {
 	return p
 }
 
-// 获得后继节点，用于简单迭代
+// 获得后继节点，用于简单迭代；不会检查迭代期间的修改（见ErrModified）
 func (this *Node) Next() *Node {
 	p := this.ptB
 	if this.mrk&1 != 0 {
@@ -96,16 +110,52 @@ func (this *Node) Key() (typeA, typeB) {
 	return this.item.Key.N, this.item.Key.S
 }
 
-// 获得节点的值，采用函数避免误修改
+// 获得节点的值，采用函数避免误修改；集合的节点没有值，返回nil
 func (this *Node) Val() typeC {
-	return this.item.Val
+	if this.entry == nil {
+		return nil
+	}
+	return this.entry.Val
 }
 
-// 设置节点的值
+// 设置节点的值，不能用于集合的节点。本方法不会更新祖先节点的聚合值，聚合树中须改用SBT.SetVal
 func (this *Node) Set(v typeC) {
 	this.item.Val = v
 }
 
+// 使键值对的节点指向其自身，返回该节点
+func (this *entry) bind() *Node {
+	this.item.entry = this
+	return &this.Node
+}
+
+// 创建保存键值对的新节点
+func newEntry(k *Key, v typeC) *Node {
+	return (&entry{Node: Node{cnt: 1, item: item{Key: *k}}, Val: v}).bind()
+}
+
+// 复制节点，新节点与原节点的键、值、聚合值和子节点指针都相同
+func (this *Node) copy() *Node {
+	if this.entry == nil {
+		p := new(Node)
+		*p = *this
+		return p
+	}
+	e := new(entry)
+	*e = *this.entry
+	return e.bind()
+}
+
+// 一次性分配n个保存键值对的节点
+func newEntries(n int) []*Node {
+	entries := make([]entry, n)
+	list := make([]*Node, n)
+	for i := range entries {
+		list[i] = entries[i].bind()
+	}
+	return list
+}
+
 // 用来以文本格式显示二叉树
 func (this *Node) Show(f func(*Node) string) (int, []string) {
 	var (
@@ -152,114 +202,138 @@ func (this *Node) Show(f func(*Node) string) (int, []string) {
 	return len(x), append(append(x, v...), y...)
 }
 
+// 对节点进行必要的旋转以维持平衡并更新大小和聚合值，返回旋转后占据该节点原位置的节点。
+// 旋转后还要依次维护下移的节点和新的子树根节点，使整棵子树重新满足大小平衡的性质。
+// 新的子树根节点继承该节点原来的父节点，但父节点的子节点指针由调用者修改。
+func (this *SBT) balance(p *Node) *Node {
+	l, r, o := p.Lson(), p.Rson(), p.ptO
+	switch {
+	case l.cnt > r.cnt:
+		b, d := l.Lson(), l.Rson()
+		if b.cnt >= d.cnt {
+			if b.cnt > r.cnt {
+				l.ptB, l.mrk = p, l.mrk|1
+				if d == null {
+					p.ptA, p.mrk = l, p.mrk&1
+				} else {
+					p.ptA, p.mrk = d, p.mrk|2
+				}
+				p.cnt = d.cnt + r.cnt + 1
+				l.cnt = b.cnt + p.cnt + 1
+				this.pull(p)
+				this.pull(l)
+				d.ptO, p.ptO, l.ptO = p, l, o
+				this.rot.single++
+				if q := this.balance(p); q != p {
+					l.ptB = q
+				}
+				return this.balance(l)
+			}
+		} else {
+			if d.cnt > r.cnt {
+				x, y := d.Lson(), d.Rson()
+				d.ptA, d.ptB, d.mrk = l, p, 3
+				if x == null {
+					l.ptB, l.mrk = d, l.mrk&2
+				} else {
+					l.ptB, l.mrk = x, l.mrk|1
+				}
+				if y == null {
+					p.ptA, p.mrk = d, p.mrk&1
+				} else {
+					p.ptA, p.mrk = y, p.mrk|2
+				}
+				l.cnt = b.cnt + x.cnt + 1
+				p.cnt = y.cnt + r.cnt + 1
+				d.cnt = l.cnt + p.cnt + 1
+				this.pull(l)
+				this.pull(p)
+				this.pull(d)
+				l.ptO, p.ptO, d.ptO = d, d, o
+				x.ptO, y.ptO = l, p
+				this.rot.double++
+				if q := this.balance(l); q != l {
+					d.ptA = q
+				}
+				if q := this.balance(p); q != p {
+					d.ptB = q
+				}
+				return this.balance(d)
+			}
+		}
+	case l.cnt < r.cnt:
+		b, d := r.Lson(), r.Rson()
+		if b.cnt <= d.cnt {
+			if d.cnt > l.cnt {
+				r.ptA, r.mrk = p, r.mrk|2
+				if b == null {
+					p.ptB, p.mrk = r, p.mrk&2
+				} else {
+					p.ptB, p.mrk = b, p.mrk|1
+				}
+				p.cnt = l.cnt + b.cnt + 1
+				r.cnt = p.cnt + d.cnt + 1
+				this.pull(p)
+				this.pull(r)
+				b.ptO, p.ptO, r.ptO = p, r, o
+				this.rot.single++
+				if q := this.balance(p); q != p {
+					r.ptA = q
+				}
+				return this.balance(r)
+			}
+		} else {
+			if b.cnt > l.cnt {
+				x, y := b.Lson(), b.Rson()
+				b.ptA, b.ptB, b.mrk = p, r, 3
+				if x == null {
+					p.ptB, p.mrk = b, p.mrk&2
+				} else {
+					p.ptB, p.mrk = x, p.mrk|1
+				}
+				if y == null {
+					r.ptA, r.mrk = b, r.mrk&1
+				} else {
+					r.ptA, r.mrk = y, r.mrk|2
+				}
+				p.cnt = l.cnt + x.cnt + 1
+				r.cnt = y.cnt + d.cnt + 1
+				b.cnt = p.cnt + r.cnt + 1
+				this.pull(p)
+				this.pull(r)
+				this.pull(b)
+				p.ptO, r.ptO, b.ptO = b, b, o
+				x.ptO, y.ptO = p, r
+				this.rot.double++
+				if q := this.balance(p); q != p {
+					b.ptA = q
+				}
+				if q := this.balance(r); q != r {
+					b.ptB = q
+				}
+				return this.balance(b)
+			}
+		}
+	}
+	p.cnt = l.cnt + r.cnt + 1
+	this.pull(p)
+	return p
+}
+
 // 维护SBT树
-func maintain(r, p *Node) *Node {
+func (this *SBT) maintain(r, p *Node) *Node {
 	var anchor = &Node{mrk: 2, ptA: r}
 	r.ptO = anchor
 	for p != anchor {
-		l, r, o := p.Lson(), p.Rson(), p.ptO
+		o := p.ptO
 		sp := (o.ptA == p)
-		switch {
-		case l.cnt > r.cnt:
-			b, d := l.Lson(), l.Rson()
-			if b.cnt >= d.cnt {
-				if b.cnt > r.cnt {
-					l.ptB, l.mrk = p, l.mrk|1
-					if d == null {
-						p.ptA, p.mrk = l, p.mrk&1
-					} else {
-						p.ptA, p.mrk = d, p.mrk|2
-					}
-					p.cnt = d.cnt + r.cnt + 1
-					l.cnt = b.cnt + p.cnt + 1
-					d.ptO, p.ptO, l.ptO = p, l, o
-					if sp {
-						o.ptA = l
-					} else {
-						o.ptB = l
-					}
-					p = o
-					continue
-				}
-			} else {
-				if d.cnt > r.cnt {
-					x, y := d.Lson(), d.Rson()
-					d.ptA, d.ptB, d.mrk = l, p, 3
-					if x == null {
-						l.ptB, l.mrk = d, l.mrk&2
-					} else {
-						l.ptB, l.mrk = x, l.mrk|1
-					}
-					if y == null {
-						p.ptA, p.mrk = d, p.mrk&1
-					} else {
-						p.ptA, p.mrk = y, p.mrk|2
-					}
-					l.cnt = b.cnt + x.cnt + 1
-					p.cnt = y.cnt + r.cnt + 1
-					d.cnt = l.cnt + p.cnt + 1
-					l.ptO, p.ptO, d.ptO = d, d, o
-					x.ptO, y.ptO = l, p
-					if sp {
-						o.ptA = d
-					} else {
-						o.ptB = d
-					}
-					p = o
-					continue
-				}
-			}
-		case l.cnt < r.cnt:
-			b, d := r.Lson(), r.Rson()
-			if b.cnt <= d.cnt {
-				if d.cnt > l.cnt {
-					r.ptA, r.mrk = p, r.mrk|2
-					if b == null {
-						p.ptB, p.mrk = r, p.mrk&2
-					} else {
-						p.ptB, p.mrk = b, p.mrk|1
-					}
-					p.cnt = l.cnt + b.cnt + 1
-					r.cnt = p.cnt + d.cnt + 1
-					b.ptO, p.ptO, r.ptO = p, r, o
-					if sp {
-						o.ptA = r
-					} else {
-						o.ptB = r
-					}
-					p = o
-					continue
-				}
+		if q := this.balance(p); q != p {
+			if sp {
+				o.ptA = q
 			} else {
-				if b.cnt > l.cnt {
-					x, y := b.Lson(), b.Rson()
-					b.ptA, b.ptB, b.mrk = p, r, 3
-					if x == null {
-						p.ptB, p.mrk = b, p.mrk&2
-					} else {
-						p.ptB, p.mrk = x, p.mrk|1
-					}
-					if y == null {
-						r.ptA, r.mrk = b, r.mrk&1
-					} else {
-						r.ptA, r.mrk = y, r.mrk|2
-					}
-					p.cnt = l.cnt + x.cnt + 1
-					r.cnt = y.cnt + d.cnt + 1
-					b.cnt = p.cnt + r.cnt + 1
-					p.ptO, r.ptO, b.ptO = b, b, o
-					x.ptO, y.ptO = p, r
-					if sp {
-						o.ptA = b
-					} else {
-						o.ptB = b
-					}
-					p = o
-					continue
-				}
+				o.ptB = q
 			}
 		}
-		p.cnt = l.cnt + r.cnt + 1
 		p = o
 	}
 	r = anchor.ptA
@@ -268,7 +342,7 @@ func maintain(r, p *Node) *Node {
 }
 
 // 通过旋转将节点转变成叶节点
-func toleaf(r, p *Node) *Node {
+func (this *SBT) toleaf(r, p *Node) *Node {
 	var anchor = &Node{mrk: 2, ptA: r}
 	r.ptO = anchor
 	l, r, o := p.Lson(), p.Rson(), p.ptO
@@ -278,6 +352,7 @@ func toleaf(r, p *Node) *Node {
 		case l.cnt > r.cnt:
 			t := l.Rson()
 			l.cnt = 0
+			this.rot.single++
 			l.ptB, l.mrk = p, l.mrk|1
 			if t == null {
 				p.ptA, p.mrk = l, p.mrk&1
@@ -298,6 +373,7 @@ func toleaf(r, p *Node) *Node {
 		default:
 			t := r.Lson()
 			r.cnt = 0
+			this.rot.single++
 			r.ptA, r.mrk = p, r.mrk|2
 			if t == null {
 				p.ptB, p.mrk = r, p.mrk&2
@@ -322,13 +398,9 @@ func New() *SBT {
 	return p
 }
 
-// 如果键已存在，更新值；如果不存在，插入新的键值对
-func (this *SBT) Update(n typeA, s typeB, v typeC) {
-	var (
-		p, q *Node
-		sp   int8
-	)
-	k := &Key{n, s}
+// 查找键对应的节点。找到时p为该节点；否则p为null，q为新节点应有的父节点（树为空时为nil），
+// sp小于0表示新节点应作为q的左子节点，大于0表示右子节点
+func (this *SBT) locate(k *Key) (p, q *Node, sp int8) {
 loop:
 	for q, p = nil, this.root; p != null; {
 		sp = compare(k, &p.item.Key)
@@ -341,15 +413,22 @@ loop:
 			break loop
 		}
 	}
-	if p != null {
-		p.item.Val = v
-		return
-	}
-	p = new(Node)
-	*p = Node{0, 1, nil, nil, q, item{*k, v}}
+	return p, q, sp
+}
+
+// 将新的键值对作为q在sp一侧的子节点加入并维护SBT树，返回新节点
+func (this *SBT) attach(q *Node, sp int8, k *Key, v typeC) *Node {
+	return this.link(q, sp, newEntry(k, v))
+}
+
+// 将新节点p作为q在sp一侧的子节点加入并维护SBT树，p的大小须为1且没有子节点，返回p
+func (this *SBT) link(q *Node, sp int8, p *Node) *Node {
+	p.ptO = q
+	this.mod++
 	if q == nil {
+		this.pull(p)
 		this.root = p
-		return
+		return p
 	}
 	if sp < 0 {
 		t := q.ptA
@@ -360,28 +439,32 @@ loop:
 		q.ptB, q.mrk = p, q.mrk|1
 		p.ptA, p.ptB = q, t
 	}
-	this.root = maintain(this.root, p)
+	this.root = this.maintain(this.root, p)
+	return p
+}
+
+// 如果键已存在，更新值；如果不存在，插入新的键值对
+func (this *SBT) Update(n typeA, s typeB, v typeC) {
+	if debug {
+		defer this.check()
+	}
+	k := &Key{n, s}
+	p, q, sp := this.locate(k)
+	if p != null {
+		p.item.Val = v
+		this.repair(p)
+		return
+	}
+	this.attach(q, sp, k, v)
 }
 
 // 不管键已存在或不存在，都插入新的键值对
 func (this *SBT) Insert(n typeA, s typeB, v typeC) {
-	var (
-		p, q *Node
-		sp   int8
-	)
-	k := &Key{n, s}
-loop:
-	for q, p = nil, this.root; p != null; {
-		sp = compare(k, &p.item.Key)
-		switch sp {
-		case -1:
-			q, p = p, p.Lson()
-		case +1:
-			q, p = p, p.Rson()
-		default:
-			break loop
-		}
+	if debug {
+		defer this.check()
 	}
+	k := &Key{n, s}
+	p, q, sp := this.locate(k)
 	if p != null {
 		l, r := p.Lson(), p.Rson()
 		if l.cnt < r.cnt {
@@ -402,30 +485,19 @@ loop:
 			}
 		}
 	}
-	p = new(Node)
-	*p = Node{0, 1, nil, nil, q, item{*k, v}}
-	if q == nil {
-		this.root = p
-		return
-	}
-	if sp < 0 {
-		t := q.ptA
-		q.ptA, q.mrk = p, q.mrk|2
-		p.ptA, p.ptB = t, q
-	} else {
-		t := q.ptB
-		q.ptB, q.mrk = p, q.mrk|1
-		p.ptA, p.ptB = q, t
-	}
-	this.root = maintain(this.root, p)
+	this.attach(q, sp, k, v)
 }
 
-// 删除节点
-func (this *SBT) Delete(p *Node) {
+// 删除节点，p须是本树中尚未被删除的节点
+func (this *SBT) DeleteNode(p *Node) {
+	if debug {
+		defer this.check()
+	}
 	if p == nil || p == null {
 		return
 	}
-	this.root = toleaf(this.root, p)
+	this.mod++
+	this.root = this.toleaf(this.root, p)
 	l, r, o := p.ptA, p.ptB, p.ptO
 	if o == nil {
 		this.root = null
@@ -436,7 +508,7 @@ func (this *SBT) Delete(p *Node) {
 	} else {
 		o.ptA, o.mrk = l, o.mrk&1
 	}
-	this.root = maintain(this.root, o)
+	this.root = this.maintain(this.root, o)
 }
 
 // 根据键查找键值对所对应的节点
@@ -514,7 +586,7 @@ func (this *SBT) Show(f func(*Node) string, spin bool) string {
 			str[i] = "    " + line
 		}
 	}
-	block := ""
+	var block strings.Builder
 	x, y := len(str), 0
 	if spin {
 		for i := 0; i < x; i++ {
@@ -544,12 +616,14 @@ func (this *SBT) Show(f func(*Node) string, spin bool) string {
 			}
 		}
 		for i := 0; i < y; i++ {
-			block += string(out[i]) + "\r\n"
+			block.Write(out[i])
+			block.WriteString("\r\n")
 		}
 	} else {
 		for i := 0; i < x; i++ {
-			block += str[i] + "\r\n"
+			block.WriteString(str[i])
+			block.WriteString("\r\n")
 		}
 	}
-	return block
+	return block.String()
 }
@@ -0,0 +1,57 @@
+package sbt
+
+import "strings"
+
+// 按键的顺序对第一部分为n、第二部分以prefix为前缀的所有键值对调用f，f返回false时停止，其间修改本树会引发panic。
+// 先下降到第一个不小于(n, prefix)的节点，再逐个向后，遇到第一个不匹配的键即停止，耗时O(log n + m)
+func (this *SBT) ScanPrefix(n typeA, prefix typeB, f func(*Node) bool) {
+	f = this.guard(f)
+	for p := this.ceil(&Key{n, prefix}); p != nil; p = p.Next() {
+		if p.item.Key.N != n || !strings.HasPrefix(p.item.Key.S, prefix) || !f(p) {
+			return
+		}
+	}
+}
+
+// 按键的顺序对第一部分为n的所有键值对调用f，f返回false时停止，其间修改本树会引发panic
+func (this *SBT) ScanN(n typeA, f func(*Node) bool) {
+	this.ScanPrefix(n, "", f)
+}
+
+// 返回键小于k的节点的数目，耗时O(log n)
+func (this *SBT) rank(k *Key) uint {
+	var n uint
+	for p := this.root; p != null; {
+		if compare(&p.item.Key, k) < 0 {
+			n += p.Lson().cnt + 1
+			p = p.Rson()
+		} else {
+			p = p.Lson()
+		}
+	}
+	return n
+}
+
+// 返回键小于(n, s)的键值对的数目，即(n, s)插入后的序号，耗时O(log n)
+func (this *SBT) Rank(n typeA, s typeB) uint {
+	return this.rank(&Key{n, s})
+}
+
+// 返回第一部分为n、第二部分以prefix为前缀的键值对的数目，借助子树大小只需两次下降，耗时O(log n)
+func (this *SBT) CountPrefix(n typeA, prefix typeB) uint {
+	lo := this.rank(&Key{n, prefix})
+	// 以prefix为前缀的字符串恰好是[prefix, next)，next为去掉末尾的0xff后将最后一个字节加一
+	b := []byte(prefix)
+	for len(b) > 0 && b[len(b)-1] == 0xff {
+		b = b[:len(b)-1]
+	}
+	switch {
+	case len(b) > 0:
+		b[len(b)-1]++
+		return this.rank(&Key{n, typeB(b)}) - lo
+	case n < n+1: // n+1没有溢出
+		return this.rank(&Key{n + 1, ""}) - lo
+	default:
+		return this.root.cnt - lo
+	}
+}
@@ -0,0 +1,96 @@
+package sbt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SBT树的深度上限，只用于发现环，正常的树远达不到
+const depth = 128
+
+// 检查以p为根的子树并按中序将节点追加到v中，o为p应有的父节点；m不为nil时还检查聚合值
+func verify(p, o *Node, m *monoid, d int, v *[]*Node) error {
+	if p == null {
+		return nil
+	}
+	if d > depth {
+		return fmt.Errorf("sbt: 树的深度超过了上限%d，可能存在环", depth)
+	}
+	if p.mrk > 3 {
+		return fmt.Errorf("sbt: 节点(%d, %s)的标记%d无效", p.item.Key.N, p.item.Key.S, p.mrk)
+	}
+	if p.ptO != o {
+		return fmt.Errorf("sbt: 节点(%d, %s)的父节点指针错误", p.item.Key.N, p.item.Key.S)
+	}
+	l, r := p.Lson(), p.Rson()
+	if err := verify(l, p, m, d+1, v); err != nil {
+		return err
+	}
+	*v = append(*v, p)
+	if err := verify(r, p, m, d+1, v); err != nil {
+		return err
+	}
+	if n := l.cnt + r.cnt + 1; p.cnt != n {
+		return fmt.Errorf("sbt: 节点(%d, %s)记录的子树大小为%d，实际为%d", p.item.Key.N, p.item.Key.S, p.cnt, n)
+	}
+	// 每棵子树的大小不小于其兄弟子树的两棵子树的大小
+	if l.cnt < r.Lson().cnt || l.cnt < r.Rson().cnt || r.cnt < l.Lson().cnt || r.cnt < l.Rson().cnt {
+		return fmt.Errorf("sbt: 节点(%d, %s)的子树大小不平衡", p.item.Key.N, p.item.Key.S)
+	}
+	if m != nil {
+		a := m.lift(p.item.Val)
+		if l != null {
+			a = m.combine(l.agg, a)
+		}
+		if r != null {
+			a = m.combine(a, r.agg)
+		}
+		if !reflect.DeepEqual(a, p.agg) {
+			return fmt.Errorf("sbt: 节点(%d, %s)记录的聚合值为%v，实际为%v", p.item.Key.N, p.item.Key.S, p.agg, a)
+		}
+	}
+	return nil
+}
+
+// 检查SBT树的各项不变式：子树大小及其平衡、父节点指针、键的顺序、线索以及聚合树的聚合值。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *SBT) Verify() error {
+	var v []*Node
+	if null.mrk != 0 || null.cnt != 0 {
+		return fmt.Errorf("sbt: 空节点被修改")
+	}
+	if err := verify(this.root, nil, this.aux, 1, &v); err != nil {
+		return err
+	}
+	for i, p := range v {
+		if i > 0 && compare(&v[i-1].item.Key, &p.item.Key) > 0 {
+			return fmt.Errorf("sbt: 第%d个节点(%d, %s)的键小于其前驱", i, p.item.Key.N, p.item.Key.S)
+		}
+		if p.mrk&2 == 0 {
+			var q *Node
+			if i > 0 {
+				q = v[i-1]
+			}
+			if p.ptA != q {
+				return fmt.Errorf("sbt: 节点(%d, %s)的前驱线索错误", p.item.Key.N, p.item.Key.S)
+			}
+		}
+		if p.mrk&1 == 0 {
+			var q *Node
+			if i+1 < len(v) {
+				q = v[i+1]
+			}
+			if p.ptB != q {
+				return fmt.Errorf("sbt: 节点(%d, %s)的后继线索错误", p.item.Key.N, p.item.Key.S)
+			}
+		}
+	}
+	return nil
+}
+
+// 调试模式下，每次修改后检查不变式，不满足时立即panic
+func (this *SBT) check() {
+	if err := this.Verify(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,81 @@
+package skiplist
+
+import "fmt"
+
+// 由已按键排好序的键值对构建跳表，返回左上角的节点。
+// 首个键值对所在的列与跳表等高，其余各列的高度随机生成，逐层接在每层的末尾。
+func build(v []*item) *Node {
+	if len(v) == 0 {
+		return nil
+	}
+	h := make([]int, len(v))
+	top := 1
+	for i := 1; i < len(v); i++ {
+		if h[i] = height(); h[i] > top {
+			top = h[i]
+		}
+	}
+	h[0] = top
+	var (
+		last [10]*Node
+		root *Node
+	)
+	for i, t := range v {
+		var q *Node
+		for j := 0; j < h[i]; j++ {
+			p := &Node{t, last[j], nil, q}
+			if last[j] != nil {
+				last[j].rgt = p
+			}
+			last[j], q = p, p
+		}
+		if i == 0 {
+			root = q
+		}
+	}
+	return root
+}
+
+// 检查键值对是否按键升序排列，再由键值对构建跳表
+func fromItems(list []*item) (*Skiplist, error) {
+	for i := 1; i < len(list); i++ {
+		if compare(&list[i].Key, &list[i-1].Key) < 0 {
+			return nil, fmt.Errorf("skiplist: 第%d个键小于前一个键，输入未按升序排列", i)
+		}
+	}
+	p := New()
+	p.root = build(list)
+	return p, nil
+}
+
+// 由按键升序排列的键值对在O(n)时间内构建跳表，vals为nil时所有值均为nil。
+// 允许存在相同的键（如同Insert）；键未排序或长度不一致时返回错误。
+func FromSorted(keys []Key, vals []typeC) (*Skiplist, error) {
+	if vals != nil && len(vals) != len(keys) {
+		return nil, fmt.Errorf("skiplist: 键的数目%d与值的数目%d不一致", len(keys), len(vals))
+	}
+	list := newEntries(len(keys))
+	for i := range keys {
+		list[i].Key = keys[i]
+		if vals != nil {
+			list[i].Val = vals[i]
+		}
+	}
+	return fromItems(list)
+}
+
+// 同FromSorted，但键值对由next依次给出，ok为false时表示序列结束
+func FromSortedFunc(next func() (k Key, v typeC, ok bool)) (*Skiplist, error) {
+	var (
+		keys []Key
+		vals []typeC
+	)
+	for {
+		k, x, ok := next()
+		if !ok {
+			break
+		}
+		keys, vals = append(keys, k), append(vals, x)
+	}
+	return FromSorted(keys, vals)
+}
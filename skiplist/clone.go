@@ -0,0 +1,65 @@
+package skiplist
+
+// 在O(n)时间内复制整个跳表，保持原有的层数与每一列的高度；
+// f不为nil时用于复制值，否则新跳表与原跳表共享值
+func (this *Skiplist) Clone(f func(typeC) typeC) *Skiplist {
+	p := New()
+	if this.root == nil {
+		return p
+	}
+	items := make(map[*item]*item)
+	var (
+		below map[*Node]*Node // 下一层中原节点到新节点的映射
+		upper *Node           // 上一层新的最左节点
+	)
+	// 由最底层开始逐层向上复制，每层都要借助下一层的映射设置dwn
+	var v []*Node
+	for q := this.root; q != nil; q = q.dwn {
+		v = append(v, q)
+	}
+	for i := len(v) - 1; i >= 0; i-- {
+		here := make(map[*Node]*Node)
+		var last *Node
+		for q := v[i]; q != nil; q = q.rgt {
+			t, ok := items[q.item]
+			if !ok {
+				t = q.item.copy()
+				if f != nil {
+					t.Val = f(q.item.Val)
+				}
+				items[q.item] = t
+			}
+			n := &Node{item: t, lft: last}
+			if below != nil {
+				n.dwn = below[q.dwn]
+			}
+			if last != nil {
+				last.rgt = n
+			}
+			here[q], last = n, n
+		}
+		below, upper = here, here[v[i]]
+	}
+	p.root = upper
+	return p
+}
+
+// 删除全部键值对
+func (this *Skiplist) Clear() {
+	this.root = nil
+	this.mod++
+}
+
+// 判断两个跳表是否按键的顺序包含相同的键值对，与各列的高度无关；eq为nil时只比较键
+func (this *Skiplist) Equal(other *Skiplist, eq func(a, b typeC) bool) bool {
+	p, q := this.Min(), other.Min()
+	for ; p != nil && q != nil; p, q = p.rgt, q.rgt {
+		if compare(&p.item.Key, &q.item.Key) != 0 {
+			return false
+		}
+		if eq != nil && !eq(p.item.Val, q.item.Val) {
+			return false
+		}
+	}
+	return p == nil && q == nil
+}
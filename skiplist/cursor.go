@@ -0,0 +1,146 @@
+package skiplist
+
+// 摘除键值对t所在的一列节点，t不能是左侧一列的键值对。
+// 逐层越过键小于t的节点，再在键相同的节点中找到属于t的那一个，因此存在相同的键时也能精确删除。
+func unlink(root *Node, t *item) {
+	for p := root; p != nil; p = p.dwn {
+		for p.rgt != nil && compare(&p.rgt.item.Key, &t.Key) < 0 {
+			p = p.rgt
+		}
+		for q := p.rgt; q != nil && compare(&q.item.Key, &t.Key) == 0; q = q.rgt {
+			if q.item == t {
+				q.lft.rgt = q.rgt
+				if q.rgt != nil {
+					q.rgt.lft = q.lft
+				}
+				break
+			}
+		}
+	}
+}
+
+// 返回第一个键不小于k的（位于最底层的）节点，没有时返回nil
+func (this *Skiplist) ceil(k *Key) *Node {
+	p := this.root
+	if p == nil || compare(&p.item.Key, k) >= 0 {
+		return this.Min()
+	}
+	for {
+		for p.rgt != nil && compare(&p.rgt.item.Key, k) < 0 {
+			p = p.rgt
+		}
+		if p.dwn == nil {
+			return p.rgt
+		}
+		p = p.dwn
+	}
+}
+
+// 跳表的游标，在最底层的节点间移动，或者处于无效位置
+type Cursor struct {
+	list *Skiplist
+	node *Node
+	mod  uint64 // 定位时容器的修改次数
+}
+
+// 创建本跳表的游标，初始处于无效位置
+func (this *Skiplist) Cursor() *Cursor {
+	return &Cursor{list: this}
+}
+
+// 移动到第一个键不小于(n, s)的节点，返回游标是否有效
+func (this *Cursor) Seek(n typeA, s typeB) bool {
+	this.mod = this.list.mod
+	this.node = this.list.ceil(&Key{n, s})
+	return this.node != nil
+}
+
+// 移动到最小键的节点，返回游标是否有效
+func (this *Cursor) SeekFirst() bool {
+	this.mod = this.list.mod
+	this.node = this.list.Min()
+	return this.node != nil
+}
+
+// 移动到最大键的节点，返回游标是否有效
+func (this *Cursor) SeekLast() bool {
+	this.mod = this.list.mod
+	this.node = this.list.Max()
+	return this.node != nil
+}
+
+// 移动到后继节点，返回游标是否有效
+func (this *Cursor) Next() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = this.node.rgt
+	}
+	return this.node != nil
+}
+
+// 移动到前驱节点，返回游标是否有效
+func (this *Cursor) Prev() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = this.node.lft
+	}
+	return this.node != nil
+}
+
+// 游标是否指向跳表中的节点
+func (this *Cursor) Valid() bool {
+	return this.node != nil
+}
+
+// 返回当前节点的键，游标无效时panic
+func (this *Cursor) Key() (typeA, typeB) {
+	this.sync()
+	return this.node.Key()
+}
+
+// 返回当前节点的值，游标无效时panic
+func (this *Cursor) Value() typeC {
+	this.sync()
+	return this.node.Val()
+}
+
+// 设置当前节点的值，游标无效时panic
+func (this *Cursor) SetValue(v typeC) {
+	this.sync()
+	this.node.Set(v)
+}
+
+// 删除当前节点并移动到其后继节点，返回游标是否有效；游标无效时什么也不做。
+// 删除的是最小的键值对时，左侧一列改由其后继顶替，游标随之指向左侧一列的最底层节点。
+func (this *Cursor) Delete() bool {
+	p := this.node
+	if p == nil {
+		return false
+	}
+	this.sync()
+	l := this.list
+	if debug {
+		defer l.check()
+	}
+	this.node = p.rgt
+	l.mod++
+	this.mod = l.mod
+	if p.item != l.root.item {
+		unlink(l.root, p.item)
+		return this.node != nil
+	}
+	if this.node == nil {
+		l.root = nil
+		return false
+	}
+	promote(l.root, this.node.item)
+	this.node = p
+	return true
+}
+
+// 确认游标定位以来容器没有被游标以外的途径插入或删除过节点，否则panic
+func (this *Cursor) sync() {
+	if this.mod != this.list.mod {
+		panic(ErrModified)
+	}
+}
@@ -0,0 +1,88 @@
+package skiplist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/hydra13142/container"
+)
+
+// 未提供标签函数时使用的节点标签
+func keyLabel(p *Node) string {
+	return fmt.Sprintf("%d, %s", p.item.Key.N, p.item.Key.S)
+}
+
+// 返回自顶向下每一层最左侧的节点，以及每个键值对在最底层中的序号，用于生成图中节点的名称
+func (this *Skiplist) lanes() ([]*Node, map[interface{}]int) {
+	var v, b []*Node
+	for p := this.root; p != nil; p = p.dwn {
+		v = append(v, p)
+	}
+	if len(v) > 0 {
+		for p := v[len(v)-1]; p != nil; p = p.rgt {
+			b = append(b, p)
+		}
+	}
+	return v, container.Number(len(b), func(i int) interface{} { return b[i].item })
+}
+
+// 以Graphviz的DOT格式输出跳表的结构，f生成节点的标签，为nil时使用键。
+// 每一层排成一行，同一键值对的节点上下相连，节点名称中的l为层号、c为在最底层中的序号。
+func (this *Skiplist) WriteDOT(w io.Writer, f func(*Node) string) error {
+	if f == nil {
+		f = keyLabel
+	}
+	v, m := this.lanes()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph skiplist {\n\tnode [shape=box];\n")
+	for i, h := range v {
+		l := len(v) - 1 - i
+		fmt.Fprintf(bw, "\tsubgraph level%d {\n\t\trank=same;\n", l)
+		for p := h; p != nil; p = p.rgt {
+			fmt.Fprintf(bw, "\t\tl%dc%d [label=\"%s\"];\n", l, m[p.item], container.DotEscape(f(p)))
+		}
+		fmt.Fprintf(bw, "\t}\n")
+		for p := h; p != nil; p = p.rgt {
+			if p.rgt != nil {
+				fmt.Fprintf(bw, "\tl%dc%d -> l%dc%d;\n", l, m[p.item], l, m[p.rgt.item])
+			}
+			if p.dwn != nil {
+				fmt.Fprintf(bw, "\tl%dc%d -> l%dc%d [style=dashed, color=gray];\n", l, m[p.item], l-1, m[p.item])
+			}
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// 以Mermaid流程图的格式输出跳表的结构，f生成节点的标签，为nil时使用键。
+// 每一层为一个横向排列的子图，同一键值对的节点以虚线上下相连。
+func (this *Skiplist) WriteMermaid(w io.Writer, f func(*Node) string) error {
+	if f == nil {
+		f = keyLabel
+	}
+	v, m := this.lanes()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "flowchart TD\n")
+	for i, h := range v {
+		l := len(v) - 1 - i
+		fmt.Fprintf(bw, "\tsubgraph level%d[\"level %d\"]\n\t\tdirection LR\n", l, l)
+		for p := h; p != nil; p = p.rgt {
+			fmt.Fprintf(bw, "\t\tl%dc%d[\"%s\"]\n", l, m[p.item], container.MermaidEscape(f(p)))
+		}
+		for p := h; p.rgt != nil; p = p.rgt {
+			fmt.Fprintf(bw, "\t\tl%dc%d --> l%dc%d\n", l, m[p.item], l, m[p.rgt.item])
+		}
+		fmt.Fprintf(bw, "\tend\n")
+	}
+	for i, h := range v {
+		l := len(v) - 1 - i
+		for p := h; p != nil; p = p.rgt {
+			if p.dwn != nil {
+				fmt.Fprintf(bw, "\tl%dc%d -.-> l%dc%d\n", l, m[p.item], l-1, m[p.item])
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,19 @@
+package skiplist
+
+import "errors"
+
+// 游标或迭代回调执行期间跳表被其他途径插入或删除了键值对时，以此为参数panic
+// 只有游标和接受回调的方法会检查，以Node.Next、Node.Prev逐个遍历节点时不检查，遍历期间不能修改跳表
+var ErrModified = errors.New("skiplist: 迭代期间跳表被修改")
+
+// 包装回调函数f，f返回后若跳表的键值对被插入或删除过则panic
+func (this *Skiplist) guard(f func(*Node) bool) func(*Node) bool {
+	m := this.mod
+	return func(p *Node) bool {
+		r := f(p)
+		if this.mod != m {
+			panic(ErrModified)
+		}
+		return r
+	}
+}
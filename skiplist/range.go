@@ -0,0 +1,112 @@
+package skiplist
+
+// 根节点所在的列保存着最小的键值对，当其被删除时，以最小的幸存键值对s顶替：
+// 要求各层中根节点与s所在节点之间已经没有其他节点，s原有的一列会被摘除。
+func promote(root *Node, s *item) {
+	for p := root; p != nil; p = p.dwn {
+		if q := p.rgt; q != nil && q.item == s {
+			p.rgt = q.rgt
+			if q.rgt != nil {
+				q.rgt.lft = p
+			}
+		}
+		p.item = s
+	}
+}
+
+// 删除键在[lo, hi)范围内的所有键值对，返回删除的数目。
+// 逐层找到范围之前的最后一个节点，将其后范围内的整段节点一次摘除。
+func (this *Skiplist) DeleteRange(lo, hi Key) int {
+	if debug {
+		defer this.check()
+	}
+	root := this.root
+	if root == nil || compare(&lo, &hi) >= 0 || compare(&root.item.Key, &hi) >= 0 {
+		return 0
+	}
+	var (
+		s *Node // 最底层中第一个未被删除的节点
+		n = 0
+	)
+	head := compare(&root.item.Key, &lo) >= 0
+	for p := root; p != nil; p = p.dwn {
+		if !head {
+			for p.rgt != nil && compare(&p.rgt.item.Key, &lo) < 0 {
+				p = p.rgt
+			}
+		}
+		q := p.rgt
+		for q != nil && compare(&q.item.Key, &hi) < 0 {
+			if q.dwn == nil {
+				n++
+			}
+			q = q.rgt
+		}
+		p.rgt = q
+		if q != nil {
+			q.lft = p
+		}
+		s = q
+	}
+	if head {
+		n++
+		if s == nil {
+			this.root = nil
+		} else {
+			promote(root, s.item)
+		}
+	}
+	if n > 0 {
+		this.mod++
+	}
+	return n
+}
+
+// 删除所有使f返回true的键值对，返回删除的数目。
+// f按键的升序以最底层的节点为参数被调用，其间修改本跳表会引发panic。
+func (this *Skiplist) DeleteFunc(f func(*Node) bool) int {
+	if debug {
+		defer this.check()
+	}
+	if this.root == nil {
+		return 0
+	}
+	var (
+		s *item // 第一个未被删除的键值对
+		p = this.root
+		d = make(map[*item]bool)
+	)
+	for p.dwn != nil {
+		p = p.dwn
+	}
+	f = this.guard(f)
+	for ; p != nil; p = p.rgt {
+		if f(p) {
+			d[p.item] = true
+		} else if s == nil {
+			s = p.item
+		}
+	}
+	if len(d) == 0 {
+		return 0
+	}
+	this.mod++
+	if d[this.root.item] && s == nil {
+		this.root = nil
+		return len(d)
+	}
+	for l := this.root; l != nil; l = l.dwn {
+		for p = l.rgt; p != nil; p = p.rgt {
+			if d[p.item] {
+				p.lft.rgt = p.rgt
+				if p.rgt != nil {
+					p.rgt.lft = p.lft
+				}
+			}
+		}
+	}
+	if d[this.root.item] {
+		promote(this.root, s)
+	}
+	return len(d)
+}
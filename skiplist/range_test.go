@@ -0,0 +1,135 @@
+package skiplist
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 按键的顺序列出跳表中全部键值对的值，相同键的各值之间不规定顺序，按值升序排列
+func values(t *Skiplist) []int {
+	var v, n []int
+	for p := t.Min(); p != nil; p = p.Next() {
+		v, n = append(v, p.Val().(int)), append(n, int(p.item.Key.N))
+	}
+	sort.Sort(byKey{v, n})
+	return v
+}
+
+// 按键再按值排列的值序列，n[i]为v[i]的键
+type byKey struct {
+	v, n []int
+}
+
+func (this byKey) Len() int {
+	return len(this.v)
+}
+
+func (this byKey) Less(i, j int) bool {
+	return this.n[i] < this.n[j] || this.n[i] == this.n[j] && this.v[i] < this.v[j]
+}
+
+func (this byKey) Swap(i, j int) {
+	this.v[i], this.v[j] = this.v[j], this.v[i]
+	this.n[i], this.n[j] = this.n[j], this.n[i]
+}
+
+// 创建键为0到9的跳表，键为N的值为N；键为5的键值对有三个，值分别为5、50、500
+func sample() *Skiplist {
+	tr := New()
+	for i := 0; i < 10; i++ {
+		tr.Insert(int64(i), "", i)
+	}
+	tr.Insert(5, "", 50)
+	tr.Insert(5, "", 500)
+	return tr
+}
+
+// 最小的键值对保存在根节点所在的列中，删除它要走promote，因此以下用例着重覆盖删除头部的情形
+func TestDeleteRange(t *testing.T) {
+	cases := []struct {
+		name   string
+		lo, hi int64
+		want   []int
+	}{
+		{"empty range", 4, 4, []int{0, 1, 2, 3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"reversed", 6, 2, []int{0, 1, 2, 3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"below head", -5, 0, []int{0, 1, 2, 3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"head", 0, 1, []int{1, 2, 3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"from below head", -5, 3, []int{3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"middle", 3, 7, []int{0, 1, 2, 7, 8, 9}},
+		{"duplicates", 5, 6, []int{0, 1, 2, 3, 4, 6, 7, 8, 9}},
+		{"tail", 8, 20, []int{0, 1, 2, 3, 4, 5, 50, 500, 6, 7}},
+		{"all but last", -1, 9, []int{9}},
+		{"all", 0, 10, nil},
+		{"all from below", -9, 99, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := sample()
+			n := tr.DeleteRange(Key{c.lo, ""}, Key{c.hi, ""})
+			if n != 12-len(c.want) {
+				t.Fatalf("DeleteRange(%d, %d) = %d; want %d", c.lo, c.hi, n, 12-len(c.want))
+			}
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if got := values(tr); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("values left %v; want %v", got, c.want)
+			}
+			// 删除后在头部之前和之后插入，检查顶替上来的根节点列仍然可用
+			tr.Insert(-1, "", -1)
+			tr.Insert(3, "", 3)
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if p := tr.Min(); p.Val() != -1 {
+				t.Fatalf("Min() = %v after inserting -1", p.Val())
+			}
+		})
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	cases := []struct {
+		name string
+		f    func(v int) bool
+		want []int
+	}{
+		{"none", func(v int) bool { return false }, []int{0, 1, 2, 3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"head", func(v int) bool { return v == 0 }, []int{1, 2, 3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"head run", func(v int) bool { return v < 3 }, []int{3, 4, 5, 50, 500, 6, 7, 8, 9}},
+		{"head and others", func(v int) bool { return v%2 == 0 }, []int{1, 3, 5, 7, 9}},
+		{"first duplicate", func(v int) bool { return v == 5 }, []int{0, 1, 2, 3, 4, 50, 500, 6, 7, 8, 9}},
+		{"later duplicates", func(v int) bool { return v >= 50 }, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"all but last", func(v int) bool { return v != 9 }, []int{9}},
+		{"all but a duplicate", func(v int) bool { return v != 500 }, []int{500}},
+		{"all", func(v int) bool { return true }, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := sample()
+			n := tr.DeleteFunc(func(p *Node) bool { return c.f(p.Val().(int)) })
+			if n != 12-len(c.want) {
+				t.Fatalf("DeleteFunc = %d; want %d", n, 12-len(c.want))
+			}
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if got := values(tr); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("values left %v; want %v", got, c.want)
+			}
+			tr.Insert(-1, "", -1)
+			tr.Insert(5, "", 5000)
+			if err := tr.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if n := tr.DeleteFunc(func(p *Node) bool { return true }); n != len(c.want)+2 {
+				t.Fatalf("deleting the rest removed %d; want %d", n, len(c.want)+2)
+			}
+			if tr.Min() != nil {
+				t.Fatal("the skiplist is not empty after deleting everything")
+			}
+		})
+	}
+}
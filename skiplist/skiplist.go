@@ -20,9 +20,16 @@ type Key struct {
 	S typeB
 }
 
-// 键值对，键值对独立出来的目的是，跳表同一键值对的节点是成列的。
+// 键，键独立出来的目的是，跳表同一键的节点是成列的。
+// 映射的键是entry的一部分，entry指向所在的entry；集合的键没有值，entry为nil
 type item struct {
 	Key
+	*entry
+}
+
+// 映射中保存键值对的单元，维护代码只使用其中的item，因此集合可以使用只有键的item
+type entry struct {
+	item
 	Val typeC
 }
 
@@ -37,7 +44,8 @@ type trace [10]*Node
 
 // 跳表类型
 type Skiplist struct {
-	root *Node // 链表左上角的节点
+	root *Node  // 链表左上角的节点
+	mod  uint64 // 插入或删除键值对的次数，用于发现迭代期间的修改
 }
 
 // 键值的比较函数
@@ -57,12 +65,12 @@ func compare(x, y *Key) int8 {
 	return 0
 }
 
-// 获得前驱节点，用于简单迭代
+// 获得前驱节点，用于简单迭代；不会检查迭代期间的修改（见ErrModified）
 func (this *Node) Prev() *Node {
 	return this.lft
 }
 
-// 获得后继节点，用于简单迭代
+// 获得后继节点，用于简单迭代；不会检查迭代期间的修改（见ErrModified）
 func (this *Node) Next() *Node {
 	return this.rgt
 }
@@ -72,16 +80,52 @@ func (this *Node) Key() (typeA, typeB) {
 	return this.N, this.S
 }
 
-// 返回当前元素的值
+// 返回当前元素的值，集合的元素没有值，返回nil
 func (this *Node) Val() typeC {
-	return this.Val
+	if this.item.entry == nil {
+		return nil
+	}
+	return this.item.Val
 }
 
-// 设置当前元素的值
+// 设置当前元素的值，不能用于集合的元素
 func (this *Node) Set(v typeC) {
 	this.item.Val = v
 }
 
+// 使键值对的item指向其自身，返回该item
+func (this *entry) bind() *item {
+	this.item.entry = this
+	return &this.item
+}
+
+// 复制键或键值对
+func (this *item) copy() *item {
+	if this.entry == nil {
+		t := new(item)
+		*t = *this
+		return t
+	}
+	e := new(entry)
+	*e = *this.entry
+	return e.bind()
+}
+
+// 创建保存键值对的单元
+func newEntry(k Key, v typeC) *item {
+	return (&entry{item{Key: k}, v}).bind()
+}
+
+// 一次性分配n个键值对
+func newEntries(n int) []*item {
+	entries := make([]entry, n)
+	list := make([]*item, n)
+	for i := range entries {
+		list[i] = entries[i].bind()
+	}
+	return list
+}
+
 // p为跳表左上角的节点，返回值，int值表示查询经历的层数，bool表示是否查询到该键。
 func (this *trace) Search(p *Node, k *Key) (int, bool) {
 	var (
@@ -193,6 +237,9 @@ func New() *Skiplist {
 
 // 如该键不存在值则插入新键值对，如已存在则更新旧值
 func (this *Skiplist) Update(n typeA, s typeB, v typeC) {
+	if debug {
+		defer this.check()
+	}
 	var tr trace
 	k := Key{n, s}
 	i, ok := tr.Search(this.root, &k)
@@ -200,13 +247,15 @@ func (this *Skiplist) Update(n typeA, s typeB, v typeC) {
 		tr[i-1].item.Val = v
 		return
 	}
-	t := new(item)
-	*t = item{k, v}
-	this.root = tr.Insert(this.root, i, t)
+	this.root = tr.Insert(this.root, i, newEntry(k, v))
+	this.mod++
 }
 
 // 插入跳表新的键值对，即使已存在该键，仍进行插入
 func (this *Skiplist) Insert(n typeA, s typeB, v typeC) {
+	if debug {
+		defer this.check()
+	}
 	var tr trace
 	k := Key{n, s}
 	i, ok := tr.Search(this.root, &k)
@@ -216,19 +265,25 @@ func (this *Skiplist) Insert(n typeA, s typeB, v typeC) {
 			i++
 		}
 	}
-	t := new(item)
-	*t = item{k, v}
-	this.root = tr.Insert(this.root, i, t)
+	this.root = tr.Insert(this.root, i, newEntry(k, v))
+	this.mod++
 }
 
-// 删除键值对
-func (this *Skiplist) Delete(n typeA, s typeB) {
+// 删除键值对，返回被删除的值以及键是否存在
+func (this *Skiplist) Delete(n typeA, s typeB) (typeC, bool) {
+	if debug {
+		defer this.check()
+	}
 	var tr trace
 	k := Key{n, s}
 	i, ok := tr.Search(this.root, &k)
-	if ok {
-		this.root = tr.Delete(this.root, i, &k)
+	if !ok {
+		return nil, false
 	}
+	v := tr[i-1].Val()
+	this.root = tr.Delete(this.root, i, &k)
+	this.mod++
+	return v, true
 }
 
 // 根据键来查找节点
@@ -0,0 +1,60 @@
+package skiplist
+
+import "unsafe"
+
+// 跳表的结构统计信息；跳表不做旋转，因此没有旋转次数
+type Stats struct {
+	Count   int     // 键值对的数目
+	Height  int     // 层数，空跳表为0
+	Nodes   int     // 各层节点的总数
+	AvgPath float64 // 平均查找路径长度，即用Search查找每个键时访问节点数的平均值
+	Memory  uintptr // 节点和键值对占用内存的估计值，不含键中的字符串和值引用的数据
+}
+
+// 按Search的方式查找键，返回访问过的节点数
+func (this *Skiplist) cost(k *Key) int {
+	var p, q *Node = this.root, nil
+	n := 1
+	if compare(k, &p.item.Key) <= 0 {
+		return n
+	}
+	for {
+		for p != nil {
+			n++
+			switch compare(&p.item.Key, k) {
+			case -1:
+				q, p = p, p.rgt
+				continue
+			case 0:
+				return n
+			}
+			break
+		}
+		if p = q.dwn; p == nil {
+			return n
+		}
+	}
+}
+
+// 返回跳表的结构统计信息，需要对每个键模拟一次查找，耗时O(n log n)
+func (this *Skiplist) Stats() Stats {
+	var (
+		s   Stats
+		sum int
+	)
+	for l := this.root; l != nil; l = l.dwn {
+		s.Height++
+		for p := l; p != nil; p = p.rgt {
+			s.Nodes++
+			if l.dwn == nil {
+				s.Count++
+				sum += this.cost(&p.item.Key)
+			}
+		}
+	}
+	if s.Count > 0 {
+		s.AvgPath = float64(sum) / float64(s.Count)
+	}
+	s.Memory = uintptr(s.Nodes)*unsafe.Sizeof(Node{}) + uintptr(s.Count)*unsafe.Sizeof(entry{})
+	return s
+}
@@ -0,0 +1,81 @@
+package skiplist
+
+// 调用回调函数f，f执行期间若跳表的键值对被插入或删除过则panic
+func (this *Skiplist) call(f func()) {
+	m := this.mod
+	f()
+	if this.mod != m {
+		panic(ErrModified)
+	}
+}
+
+// 插入或更新键值对，返回原有的值以及键是否已存在
+func (this *Skiplist) Put(n typeA, s typeB, v typeC) (old typeC, replaced bool) {
+	if debug {
+		defer this.check()
+	}
+	var tr trace
+	k := Key{n, s}
+	i, ok := tr.Search(this.root, &k)
+	if ok {
+		t := tr[i-1].item
+		old, t.Val = t.Val, v
+		return old, true
+	}
+	this.root = tr.Insert(this.root, i, newEntry(k, v))
+	this.mod++
+	return nil, false
+}
+
+// 键已存在时返回其值，loaded为true；否则插入f返回的值并返回之，loaded为false。
+// f只在键不存在时被调用一次，其间修改本跳表会引发panic
+func (this *Skiplist) GetOrInsert(n typeA, s typeB, f func() typeC) (v typeC, loaded bool) {
+	if debug {
+		defer this.check()
+	}
+	var tr trace
+	k := Key{n, s}
+	i, ok := tr.Search(this.root, &k)
+	if ok {
+		return tr[i-1].item.Val, true
+	}
+	this.call(func() { v = f() })
+	this.root = tr.Insert(this.root, i, newEntry(k, v))
+	this.mod++
+	return v, false
+}
+
+// 以键原有的值（键不存在时old为nil、ok为false）调用f，keep为true时将键的值设为f返回的值，
+// 为false时删除该键（键不存在时什么也不做）。只查找一次，返回键最终的值以及键是否存在。
+// f执行期间修改本跳表会引发panic
+func (this *Skiplist) Compute(n typeA, s typeB, f func(old typeC, ok bool) (v typeC, keep bool)) (typeC, bool) {
+	if debug {
+		defer this.check()
+	}
+	var (
+		tr   trace
+		v    typeC
+		keep bool
+		old  typeC
+	)
+	k := Key{n, s}
+	i, ok := tr.Search(this.root, &k)
+	if ok {
+		old = tr[i-1].item.Val
+	}
+	this.call(func() { v, keep = f(old, ok) })
+	switch {
+	case !keep:
+		if ok {
+			this.root = tr.Delete(this.root, i, &k)
+			this.mod++
+		}
+		return nil, false
+	case ok:
+		tr[i-1].item.Val = v
+	default:
+		this.root = tr.Insert(this.root, i, newEntry(k, v))
+		this.mod++
+	}
+	return v, true
+}
@@ -0,0 +1,67 @@
+package skiplist
+
+import "fmt"
+
+// 检查跳表的各项不变式：层数、每层链表的双向链接与键的顺序、
+// 每列节点共享同一键值对且都能向下到达最底层、左侧一列保存最小的键值对。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *Skiplist) Verify() error {
+	if this.root == nil {
+		return nil
+	}
+	var v []*Node
+	for p := this.root; p != nil; p = p.dwn {
+		if len(v) == len(trace{}) {
+			return fmt.Errorf("skiplist: 层数超过了上限%d", len(trace{}))
+		}
+		v = append(v, p)
+	}
+	// 下一层中所有节点的集合，用于确认dwn指针确实指向了下一层
+	var below map[*Node]bool
+	for i := len(v) - 1; i >= 0; i-- {
+		here := make(map[*Node]bool)
+		if v[i].lft != nil {
+			return fmt.Errorf("skiplist: 第%d层最左侧的节点存在左邻", len(v)-1-i)
+		}
+		for p := v[i]; p != nil; p = p.rgt {
+			if here[p] {
+				return fmt.Errorf("skiplist: 第%d层中存在环", len(v)-1-i)
+			}
+			here[p] = true
+			if p.item == nil {
+				return fmt.Errorf("skiplist: 第%d层中存在没有键值对的节点", len(v)-1-i)
+			}
+			if p.item != this.root.item && compare(&p.item.Key, &this.root.item.Key) < 0 {
+				return fmt.Errorf("skiplist: 节点(%d, %s)的键小于左侧一列的键", p.item.Key.N, p.item.Key.S)
+			}
+			if q := p.rgt; q != nil {
+				if q.lft != p {
+					return fmt.Errorf("skiplist: 节点(%d, %s)与其右邻的双向链接不一致", p.item.Key.N, p.item.Key.S)
+				}
+				if compare(&p.item.Key, &q.item.Key) > 0 {
+					return fmt.Errorf("skiplist: 第%d层中节点(%d, %s)的键大于其右邻", len(v)-1-i, p.item.Key.N, p.item.Key.S)
+				}
+			}
+			switch {
+			case below == nil && p.dwn != nil:
+				return fmt.Errorf("skiplist: 最底层的节点(%d, %s)存在下层节点", p.item.Key.N, p.item.Key.S)
+			case below != nil && !below[p.dwn]:
+				return fmt.Errorf("skiplist: 节点(%d, %s)的下层节点不在下一层中", p.item.Key.N, p.item.Key.S)
+			case below != nil && p.dwn.item != p.item:
+				return fmt.Errorf("skiplist: 节点(%d, %s)与其下层节点的键值对不同", p.item.Key.N, p.item.Key.S)
+			}
+		}
+		if v[i].item != this.root.item {
+			return fmt.Errorf("skiplist: 第%d层最左侧的节点不属于左侧一列", len(v)-1-i)
+		}
+		below = here
+	}
+	return nil
+}
+
+// 调试模式下，每次修改后检查不变式，不满足时立即panic
+func (this *Skiplist) check() {
+	if err := this.Verify(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,125 @@
+package table
+
+import (
+	"github.com/hydra13142/container/sbt"
+	"github.com/hydra13142/container/skiplist"
+)
+
+// 索引所用的容器
+type Backend uint8
+
+const (
+	SBT      Backend = iota // 以sbt.SBT保存，计数和跳过offset行都只需O(log n)
+	Skiplist                // 以skiplist.Skiplist保存，计数和跳过offset行需要逐个遍历
+)
+
+// 表和索引的有序存储，各容器的键统一用sbt.Key表示
+type store interface {
+	put(k Key, v interface{})
+	del(k Key)
+	get(k Key) (interface{}, bool)
+	// 从第一个不小于lo的键开始，跳过skip个键值对后按升序调用f，f返回false时停止
+	scan(lo Key, skip int, f func(k Key, v interface{}) bool)
+	// 键在[lo, hi)范围内的键值对的数目，hi为nil时没有上界
+	count(lo Key, hi *Key) int
+}
+
+// 以SBT树实现的存储
+type tree struct {
+	t *sbt.SBT
+}
+
+func (this tree) put(k Key, v interface{}) {
+	this.t.Update(k.N, k.S, v)
+}
+
+func (this tree) del(k Key) {
+	this.t.Delete(k.N, k.S)
+}
+
+func (this tree) get(k Key) (interface{}, bool) {
+	if p := this.t.Search(k.N, k.S); p != nil {
+		return p.Val(), true
+	}
+	return nil, false
+}
+
+// 由子树大小直接定位到第rank(lo)+skip个节点
+func (this tree) scan(lo Key, skip int, f func(k Key, v interface{}) bool) {
+	for p := this.t.Index(this.t.Rank(lo.N, lo.S) + uint(skip)); p != nil; p = p.Next() {
+		n, s := p.Key()
+		if !f(Key{N: n, S: s}, p.Val()) {
+			return
+		}
+	}
+}
+
+// 两次Rank相减，耗时O(log n)
+func (this tree) count(lo Key, hi *Key) int {
+	n := 0
+	if hi != nil {
+		n = int(this.t.Rank(hi.N, hi.S))
+	} else if p := this.t.Max(); p != nil {
+		k, s := p.Key()
+		n = int(this.t.Rank(k, s)) + 1
+	}
+	if n -= int(this.t.Rank(lo.N, lo.S)); n < 0 {
+		n = 0
+	}
+	return n
+}
+
+// 以跳表实现的存储
+type list struct {
+	l *skiplist.Skiplist
+}
+
+func (this list) put(k Key, v interface{}) {
+	this.l.Update(k.N, k.S, v)
+}
+
+func (this list) del(k Key) {
+	this.l.Delete(k.N, k.S)
+}
+
+func (this list) get(k Key) (interface{}, bool) {
+	if p := this.l.Search(k.N, k.S); p != nil {
+		return p.Val(), true
+	}
+	return nil, false
+}
+
+func (this list) scan(lo Key, skip int, f func(k Key, v interface{}) bool) {
+	c := this.l.Cursor()
+	for ok := c.Seek(lo.N, lo.S); ok; ok = c.Next() {
+		if skip > 0 {
+			skip--
+			continue
+		}
+		n, s := c.Key()
+		if !f(Key{N: n, S: s}, c.Value()) {
+			return
+		}
+	}
+}
+
+// 跳表没有子树大小，只能定位到lo之后逐个数到hi，耗时O(log n + k)，k为范围内的键值对数
+func (this list) count(lo Key, hi *Key) int {
+	r := 0
+	this.scan(lo, 0, func(k Key, v interface{}) bool {
+		if hi != nil && !less(k, *hi) {
+			return false
+		}
+		r++
+		return true
+	})
+	return r
+}
+
+// 创建指定容器的存储
+func open(b Backend) store {
+	if b == Skiplist {
+		return list{skiplist.New()}
+	}
+	return tree{sbt.New()}
+}
@@ -0,0 +1,433 @@
+// table包在各容器之上提供内存中的表：按列定义行，主键映射为Key{N, S}保存在sbt.SBT中，
+// 可以建立任意多个以sbt.SBT或skiplist.Skiplist保存的二级索引，
+// 并按任意索引进行点查询、范围查询以及带offset和limit的有序扫描
+package table
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hydra13142/container"
+	"github.com/hydra13142/container/sbt"
+)
+
+// 表和索引的键
+type Key = sbt.Key
+
+// 列的类型
+type Kind uint8
+
+const (
+	Int    Kind = iota // int64
+	Float              // float64
+	String             // string
+	Bytes              // []byte
+	Time               // time.Time
+)
+
+// 列的定义
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// 表中的一行，各元素依次对应各列
+type Row []interface{}
+
+// 主键索引的名称，可用于各查询方法
+const Primary = ""
+
+// 索引，主键索引的值为行，二级索引的值为行的主键
+type index struct {
+	name   string
+	cols   []int
+	unique bool
+	data   store
+}
+
+// 内存中的表
+type Table struct {
+	cols []Column
+	name map[string]int // 列名到列序号
+	prim *index
+	idx  map[string]*index
+	list []*index // 二级索引，按建立的顺序
+	size int
+}
+
+// 键x是否小于y
+func less(x, y Key) bool {
+	return x.N < y.N || x.N == y.N && x.S < y.S
+}
+
+// 创建一个表，primary为构成主键的各列的名称。
+// 主键只有一个Int列时映射为Key{N: 值}，否则映射为Key{S: 各列的值构成的元组的保序编码}
+func New(cols []Column, primary ...string) (*Table, error) {
+	if len(cols) == 0 || len(primary) == 0 {
+		return nil, fmt.Errorf("table: 表至少要有一列，主键至少要有一列")
+	}
+	t := &Table{cols: append([]Column(nil), cols...), name: map[string]int{}, idx: map[string]*index{}}
+	for i, c := range cols {
+		if _, ok := t.name[c.Name]; ok {
+			return nil, fmt.Errorf("table: 列%s重复", c.Name)
+		}
+		if c.Kind > Time {
+			return nil, fmt.Errorf("table: 列%s的类型%d无效", c.Name, c.Kind)
+		}
+		t.name[c.Name] = i
+	}
+	pk, err := t.columns(primary)
+	if err != nil {
+		return nil, err
+	}
+	t.prim = &index{Primary, pk, true, tree{sbt.New()}}
+	return t, nil
+}
+
+// 由列名得到列序号
+func (this *Table) columns(names []string) ([]int, error) {
+	v := make([]int, len(names))
+	for i, s := range names {
+		c, ok := this.name[s]
+		if !ok {
+			return nil, fmt.Errorf("table: 列%s不存在", s)
+		}
+		v[i] = c
+	}
+	return v, nil
+}
+
+// 检查值x是否符合第c列的类型
+func (this *Table) check(c int, x interface{}) error {
+	var ok bool
+	switch this.cols[c].Kind {
+	case Int:
+		_, ok = x.(int64)
+	case Float:
+		_, ok = x.(float64)
+	case String:
+		_, ok = x.(string)
+	case Bytes:
+		_, ok = x.([]byte)
+	case Time:
+		_, ok = x.(time.Time)
+	}
+	if !ok {
+		return fmt.Errorf("table: 列%s不能保存%T类型的值", this.cols[c].Name, x)
+	}
+	return nil
+}
+
+// 检查行的列数和各列的类型
+func (this *Table) valid(r Row) error {
+	if len(r) != len(this.cols) {
+		return fmt.Errorf("table: 行有%d列，表有%d列", len(r), len(this.cols))
+	}
+	for i := range r {
+		if err := this.check(i, r[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 复制行，Bytes列的切片也一并复制，使表内保存的行与调用者持有的行互不影响
+func clone(r Row) Row {
+	c := append(Row(nil), r...)
+	for i, x := range c {
+		if b, ok := x.([]byte); ok {
+			c[i] = append([]byte(nil), b...)
+		}
+	}
+	return c
+}
+
+// 主键是否只有一个Int列，此时主键映射为Key{N: 值}
+func (this *Table) integral() bool {
+	return len(this.prim.cols) == 1 && this.cols[this.prim.cols[0]].Kind == Int
+}
+
+// 由索引ix的前若干列的值得到索引中的键（或者查询的界），值的数目或类型不符时返回错误
+func (this *Table) bound(ix *index, vals []interface{}) (Key, error) {
+	if len(vals) > len(ix.cols) {
+		return Key{}, fmt.Errorf("table: 索引%s只有%d列，给出了%d个值", ix.name, len(ix.cols), len(vals))
+	}
+	for i, x := range vals {
+		if err := this.check(ix.cols[i], x); err != nil {
+			return Key{}, err
+		}
+	}
+	if ix == this.prim && this.integral() {
+		if len(vals) == 0 {
+			return Key{N: math.MinInt64}, nil
+		}
+		return Key{N: vals[0].(int64)}, nil
+	}
+	return Key{S: container.Tuple(vals).Encode()}, nil
+}
+
+// 行r在索引ix中的键：主键索引中即为主键；二级索引中是索引各列之后接主键各列所构成的元组的保序编码，
+// 因此相同索引值的各行按主键排列，并且共有索引值编码的前缀
+func (this *Table) entry(ix *index, r Row) Key {
+	if ix == this.prim && this.integral() {
+		return Key{N: r[ix.cols[0]].(int64)}
+	}
+	t := make(container.Tuple, 0, len(ix.cols)+len(this.prim.cols))
+	for _, c := range ix.cols {
+		t = append(t, r[c])
+	}
+	if ix != this.prim {
+		for _, c := range this.prim.cols {
+			t = append(t, r[c])
+		}
+	}
+	return Key{S: t.Encode()}
+}
+
+// 唯一索引ix中与行r的索引值相同的行的主键若不是pk，返回错误
+func (this *Table) vacant(ix *index, r Row, pk Key) error {
+	if !ix.unique {
+		return nil
+	}
+	t := make(container.Tuple, len(ix.cols))
+	for i, c := range ix.cols {
+		t[i] = r[c]
+	}
+	p := Key{S: t.Encode()}
+	var err error
+	ix.data.scan(p, 0, func(k Key, v interface{}) bool {
+		if k.N != p.N || !strings.HasPrefix(k.S, p.S) {
+			return false
+		}
+		if o := v.(Key); o != pk {
+			err = fmt.Errorf("table: 索引%s中已有相同的值，属于主键为(%d, %q)的行", ix.name, o.N, o.S)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// 建立名为name的二级索引，cols为索引的各列，unique为true时不同的行不能有相同的索引值，b为保存索引的容器。
+// 已有的行会被加入索引；名称重复、列不存在或已有的行违反唯一性时返回错误，此时不建立索引
+func (this *Table) AddIndex(name string, cols []string, unique bool, b Backend) error {
+	if _, ok := this.idx[name]; ok || name == Primary {
+		return fmt.Errorf("table: 索引%q已存在", name)
+	}
+	c, err := this.columns(cols)
+	if err != nil {
+		return err
+	}
+	if len(c) == 0 {
+		return fmt.Errorf("table: 索引%s至少要有一列", name)
+	}
+	ix := &index{name, c, unique, open(b)}
+	this.prim.data.scan(Key{N: math.MinInt64}, 0, func(k Key, v interface{}) bool {
+		r := v.(Row)
+		if err = this.vacant(ix, r, k); err == nil {
+			ix.data.put(this.entry(ix, r), k)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	this.idx[name] = ix
+	this.list = append(this.list, ix)
+	return nil
+}
+
+// 插入新的行，行无效、主键已存在或违反唯一索引时返回错误，此时表不变
+func (this *Table) Insert(r Row) error {
+	return this.put(r, false)
+}
+
+// 主键已存在时替换该行，否则插入新的行；行无效或违反唯一索引时返回错误，此时表不变
+func (this *Table) Update(r Row) error {
+	return this.put(r, true)
+}
+
+// 先检查全部约束再修改，保证出错时没有任何改动
+func (this *Table) put(r Row, replace bool) error {
+	if err := this.valid(r); err != nil {
+		return err
+	}
+	r = clone(r)
+	pk := this.entry(this.prim, r)
+	v, ok := this.prim.data.get(pk)
+	if ok && !replace {
+		return fmt.Errorf("table: 主键为(%d, %q)的行已存在", pk.N, pk.S)
+	}
+	for _, ix := range this.list {
+		if err := this.vacant(ix, r, pk); err != nil {
+			return err
+		}
+	}
+	if ok {
+		for _, ix := range this.list {
+			ix.data.del(this.entry(ix, v.(Row)))
+		}
+	} else {
+		this.size++
+	}
+	this.prim.data.put(pk, r)
+	for _, ix := range this.list {
+		ix.data.put(this.entry(ix, r), pk)
+	}
+	return nil
+}
+
+// 根据主键各列的值查找行
+func (this *Table) Get(pk ...interface{}) (Row, bool) {
+	if len(pk) != len(this.prim.cols) {
+		return nil, false
+	}
+	k, err := this.bound(this.prim, pk)
+	if err != nil {
+		return nil, false
+	}
+	v, ok := this.prim.data.get(k)
+	if !ok {
+		return nil, false
+	}
+	return clone(v.(Row)), true
+}
+
+// 根据主键各列的值删除行，返回被删除的行以及该行是否存在
+func (this *Table) Delete(pk ...interface{}) (Row, bool) {
+	if len(pk) != len(this.prim.cols) {
+		return nil, false
+	}
+	k, err := this.bound(this.prim, pk)
+	if err != nil {
+		return nil, false
+	}
+	v, ok := this.prim.data.get(k)
+	if !ok {
+		return nil, false
+	}
+	r := v.(Row)
+	for _, ix := range this.list {
+		ix.data.del(this.entry(ix, r))
+	}
+	this.prim.data.del(k)
+	this.size--
+	return clone(r), true
+}
+
+// 返回行的数目
+func (this *Table) Len() int {
+	return this.size
+}
+
+// 返回名为name的索引，name为Primary时返回主键索引
+func (this *Table) index(name string) (*index, error) {
+	if name == Primary {
+		return this.prim, nil
+	}
+	if ix, ok := this.idx[name]; ok {
+		return ix, nil
+	}
+	return nil, fmt.Errorf("table: 索引%q不存在", name)
+}
+
+// 由索引中的值得到行
+func (this *Table) row(ix *index, v interface{}) Row {
+	if ix == this.prim {
+		return v.(Row)
+	}
+	r, _ := this.prim.data.get(v.(Key))
+	return r.(Row)
+}
+
+// 按索引name的顺序返回前若干列的值为vals的所有行，vals可以只给出索引的前几列
+func (this *Table) Lookup(name string, vals ...interface{}) ([]Row, error) {
+	ix, err := this.index(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := this.bound(ix, vals)
+	if err != nil {
+		return nil, err
+	}
+	var rs []Row
+	ix.data.scan(p, 0, func(k Key, v interface{}) bool {
+		if ix == this.prim && this.integral() {
+			if len(vals) > 0 && k.N != p.N {
+				return false
+			}
+		} else if k.N != p.N || !strings.HasPrefix(k.S, p.S) {
+			return false
+		}
+		rs = append(rs, clone(this.row(ix, v)))
+		return true
+	})
+	return rs, nil
+}
+
+// 由索引name和界lo、hi得到索引中的键的范围，hi为nil时没有上界
+func (this *Table) span(name string, lo, hi container.Tuple) (ix *index, l Key, h *Key, err error) {
+	if ix, err = this.index(name); err != nil {
+		return
+	}
+	if l, err = this.bound(ix, lo); err != nil {
+		return
+	}
+	if hi != nil {
+		var k Key
+		if k, err = this.bound(ix, hi); err != nil {
+			return
+		}
+		h = &k
+	}
+	return
+}
+
+// 按索引name的顺序，返回索引值在[lo, hi)范围内的行中跳过前offset行之后的至多limit行。
+// lo、hi可以只给出索引的前几列，按元组的顺序比较，lo为nil时没有下界，hi为nil时没有上界；limit小于0时不限行数。
+// 以SBT保存的索引（包括主键索引）跳过offset行只需O(log n)
+func (this *Table) Range(name string, lo, hi container.Tuple, offset, limit int) ([]Row, error) {
+	ix, l, h, err := this.span(name, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	var rs []Row
+	if offset < 0 {
+		offset = 0
+	}
+	ix.data.scan(l, offset, func(k Key, v interface{}) bool {
+		if limit >= 0 && len(rs) >= limit || h != nil && !less(k, *h) {
+			return false
+		}
+		rs = append(rs, clone(this.row(ix, v)))
+		return true
+	})
+	return rs, nil
+}
+
+// 返回索引name中索引值在[lo, hi)范围内的行数，界的含义同Range。
+// 以SBT保存的索引（包括主键索引）耗时O(log n)；以跳表保存的索引要逐行计数，耗时O(log n + k)，k为结果
+func (this *Table) Count(name string, lo, hi container.Tuple) (int, error) {
+	ix, l, h, err := this.span(name, lo, hi)
+	if err != nil {
+		return 0, err
+	}
+	return ix.data.count(l, h), nil
+}
+
+// 按主键的顺序，跳过前offset行后对至多limit行调用f，limit小于0时不限行数，f返回false时停止。
+// 借助SBT.Index在O(log n)时间内定位到第offset行；f执行期间不能修改本表
+func (this *Table) Scan(offset, limit int, f func(Row) bool) {
+	if offset < 0 {
+		offset = 0
+	}
+	this.prim.data.scan(Key{N: math.MinInt64}, offset, func(k Key, v interface{}) bool {
+		if limit == 0 {
+			return false
+		}
+		limit--
+		return f(clone(v.(Row)))
+	})
+}
@@ -0,0 +1,192 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hydra13142/container"
+)
+
+var backends = []struct {
+	name string
+	b    Backend
+}{{"sbt", SBT}, {"skiplist", Skiplist}}
+
+// 创建以id为主键的表，name上有唯一索引，(age, name)上有非唯一索引，两个索引都以b保存
+func people(t *testing.T, b Backend) *Table {
+	tb, err := New([]Column{{"id", Int}, {"name", String}, {"age", Int}}, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tb.AddIndex("name", []string{"name"}, true, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := tb.AddIndex("age", []string{"age", "name"}, false, b); err != nil {
+		t.Fatal(err)
+	}
+	return tb
+}
+
+// 按主键的顺序列出表中的全部行
+func rows(tb *Table) []Row {
+	var rs []Row
+	tb.Scan(0, -1, func(r Row) bool {
+		rs = append(rs, r)
+		return true
+	})
+	return rs
+}
+
+func TestRangeBounds(t *testing.T) {
+	for _, bk := range backends {
+		t.Run(bk.name, func(t *testing.T) {
+			tb := people(t, bk.b)
+			for i := int64(0); i < 24; i++ {
+				if err := tb.Insert(Row{i, fmt.Sprintf("n%02d", (i*7)%24), i % 5}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			all := rows(tb)
+			// 由(age, name, id)构成的完整元组，索引age中的行按它排列
+			full := func(r Row) container.Tuple { return container.Tuple{r[2], r[1], r[0]} }
+			sort.Slice(all, func(i, j int) bool { return full(all[i]).Compare(full(all[j])) < 0 })
+			bounds := []container.Tuple{
+				nil,
+				{},
+				{int64(-1)},
+				{int64(0)},
+				{int64(2)},
+				{int64(2), "n07"},
+				{int64(2), "n08"},
+				{int64(4)},
+				{int64(5)},
+			}
+			for _, lo := range bounds {
+				for _, hi := range bounds {
+					var want []Row
+					for _, r := range all {
+						if full(r).Compare(lo) >= 0 && (hi == nil || full(r).Compare(hi) < 0) {
+							want = append(want, r)
+						}
+					}
+					got, err := tb.Range("age", lo, hi, 0, -1)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+						t.Fatalf("Range(%v, %v) = %v; want %v", lo, hi, got, want)
+					}
+					n, err := tb.Count("age", lo, hi)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if n != len(want) {
+						t.Fatalf("Count(%v, %v) = %d; want %d", lo, hi, n, len(want))
+					}
+					if len(want) > 2 {
+						got, _ = tb.Range("age", lo, hi, 1, 2)
+						if !reflect.DeepEqual(got, want[1:3]) {
+							t.Fatalf("Range(%v, %v, 1, 2) = %v; want %v", lo, hi, got, want[1:3])
+						}
+					}
+				}
+			}
+			// 主键只有一个Int列，界直接映射为Key{N: 值}
+			got, _ := tb.Range(Primary, container.Tuple{int64(3)}, container.Tuple{int64(6)}, 0, -1)
+			if len(got) != 3 || got[0][0] != int64(3) || got[2][0] != int64(5) {
+				t.Fatalf("Range(Primary, 3, 6) = %v; want ids 3, 4, 5", got)
+			}
+			if n, _ := tb.Count(Primary, container.Tuple{int64(3)}, container.Tuple{int64(6)}); n != 3 {
+				t.Fatalf("Count(Primary, 3, 6) = %d; want 3", n)
+			}
+			if n, _ := tb.Count(Primary, container.Tuple{int64(20)}, nil); n != 4 {
+				t.Fatalf("Count(Primary, 20, nil) = %d; want 4", n)
+			}
+		})
+	}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	for _, bk := range backends {
+		t.Run(bk.name, func(t *testing.T) {
+			tb := people(t, bk.b)
+			for _, r := range []Row{{int64(1), "ann", int64(30)}, {int64(2), "bob", int64(40)}} {
+				if err := tb.Insert(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before := rows(tb)
+			for _, c := range []struct {
+				op string
+				r  Row
+			}{
+				{"insert", Row{int64(3), "ann", int64(50)}},
+				{"insert", Row{int64(1), "cat", int64(50)}},
+				{"update", Row{int64(2), "ann", int64(50)}},
+				{"update", Row{int64(3), "bob", int64(50)}},
+			} {
+				var err error
+				if c.op == "insert" {
+					err = tb.Insert(c.r)
+				} else {
+					err = tb.Update(c.r)
+				}
+				if err == nil {
+					t.Fatalf("%s %v succeeded", c.op, c.r)
+				}
+				if got := rows(tb); !reflect.DeepEqual(got, before) {
+					t.Fatalf("after failed %s %v the rows are %v; want %v", c.op, c.r, got, before)
+				}
+				if tb.Len() != 2 {
+					t.Fatalf("Len() = %d after failed %s; want 2", tb.Len(), c.op)
+				}
+				for _, r := range before {
+					got, _ := tb.Lookup("name", r[1])
+					if len(got) != 1 || !reflect.DeepEqual(got[0], r) {
+						t.Fatalf("Lookup(name, %v) = %v after failed %s; want %v", r[1], got, c.op, r)
+					}
+				}
+				if n, _ := tb.Count("age", container.Tuple{int64(50)}, nil); n != 0 {
+					t.Fatalf("index age has %d entries for age 50 after failed %s", n, c.op)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateMovesIndexEntries(t *testing.T) {
+	for _, bk := range backends {
+		t.Run(bk.name, func(t *testing.T) {
+			tb := people(t, bk.b)
+			tb.Insert(Row{int64(1), "ann", int64(30)})
+			tb.Insert(Row{int64(2), "bob", int64(30)})
+			if err := tb.Update(Row{int64(1), "amy", int64(35)}); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := tb.Lookup("name", "ann"); len(got) != 0 {
+				t.Fatalf("Lookup(name, ann) = %v; want nothing", got)
+			}
+			if got, _ := tb.Lookup("name", "amy"); len(got) != 1 || got[0][0] != int64(1) {
+				t.Fatalf("Lookup(name, amy) = %v; want row 1", got)
+			}
+			if got, _ := tb.Lookup("age", int64(30)); len(got) != 1 || got[0][0] != int64(2) {
+				t.Fatalf("Lookup(age, 30) = %v; want only row 2", got)
+			}
+			if got, _ := tb.Lookup("age", int64(35), "amy"); len(got) != 1 || got[0][0] != int64(1) {
+				t.Fatalf("Lookup(age, 35, amy) = %v; want row 1", got)
+			}
+			if n, _ := tb.Count("age", nil, nil); n != 2 {
+				t.Fatalf("index age has %d entries; want 2", n)
+			}
+			// 唯一索引中原来的值已经释放，可以由其他行使用
+			if err := tb.Insert(Row{int64(3), "ann", int64(30)}); err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := tb.Count("name", nil, nil); n != 3 {
+				t.Fatalf("index name has %d entries; want 3", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package treap
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// 将已按键排好序的节点序列构建为笛卡尔树，返回根节点。
+// 借助一个栈保存当前的右链，每个节点至多入栈、出栈各一次，因此是O(n)的。
+func build(v []*Node) *Node {
+	st := make([]*Node, 0, 64)
+	for _, p := range v {
+		p.Lsn, p.Rsn, p.Dad = null, null, null
+		l := null
+		for len(st) > 0 && st[len(st)-1].wgt > p.wgt {
+			l = st[len(st)-1]
+			st = st[:len(st)-1]
+		}
+		if l != null {
+			p.Lsn, l.Dad = l, p
+		}
+		if len(st) > 0 {
+			q := st[len(st)-1]
+			q.Rsn, p.Dad = p, q
+		}
+		st = append(st, p)
+	}
+	if len(st) == 0 {
+		return null
+	}
+	return st[0]
+}
+
+// 检查键值对是否按键升序排列，并一次性分配全部节点后构建树堆
+func fromItems(w []int64, v []item) (*Treap, error) {
+	for i := 1; i < len(v); i++ {
+		if compare(&v[i].Key, &v[i-1].Key) < 0 {
+			return nil, fmt.Errorf("treap: 第%d个键小于前一个键，输入未按升序排列", i)
+		}
+	}
+	nodes := make([]Node, len(v))
+	list := make([]*Node, len(v))
+	for i := range v {
+		nodes[i].wgt, nodes[i].item = w[i], v[i]
+		list[i] = &nodes[i]
+	}
+	p := NewTreap()
+	p.root = build(list)
+	return p, nil
+}
+
+// 由按键升序排列的键值对及其优先级在O(n)时间内构建树堆，vals为nil时所有值均为nil。
+// 允许存在相同的键（如同Insert）；键未排序或长度不一致时返回错误。
+func FromSorted(wgts []int64, keys []Key, vals []typeC) (*Treap, error) {
+	if len(wgts) != len(keys) || vals != nil && len(vals) != len(keys) {
+		return nil, fmt.Errorf("treap: 优先级、键、值的数目不一致")
+	}
+	v := make([]item, len(keys))
+	for i := range keys {
+		v[i].Key = keys[i]
+		if vals != nil {
+			v[i].Val = vals[i]
+		}
+	}
+	return fromItems(wgts, v)
+}
+
+// 同FromSorted，但优先级和键值对由next依次给出，ok为false时表示序列结束
+func FromSortedFunc(next func() (w int64, k Key, v typeC, ok bool)) (*Treap, error) {
+	var (
+		w []int64
+		v []item
+	)
+	for {
+		t, k, x, ok := next()
+		if !ok {
+			break
+		}
+		w, v = append(w, t), append(v, item{k, x})
+	}
+	return fromItems(w, v)
+}
+
+// 由按键升序排列的键值对在O(n)时间内构建二叉搜索树，优先级随机生成
+func FromSortedBST(keys []Key, vals []typeC) (*BST, error) {
+	w := make([]int64, len(keys))
+	for i := range w {
+		w[i] = rand.Int63()
+	}
+	t, err := FromSorted(w, keys, vals)
+	if err != nil {
+		return nil, err
+	}
+	return &BST{*t}, nil
+}
+
+// 同FromSortedBST，但键值对由next依次给出，ok为false时表示序列结束
+func FromSortedBSTFunc(next func() (k Key, v typeC, ok bool)) (*BST, error) {
+	t, err := FromSortedFunc(func() (int64, Key, typeC, bool) {
+		k, v, ok := next()
+		return rand.Int63(), k, v, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BST{*t}, nil
+}
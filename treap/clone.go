@@ -0,0 +1,127 @@
+package treap
+
+import "sort"
+
+// 复制以p为根的子树，f不为nil时用于复制值；新子树根节点的父节点为null
+func clone(p *Node, f func(typeC) typeC) *Node {
+	if p == null {
+		return null
+	}
+	q := new(Node)
+	*q = *p
+	if f != nil {
+		q.item.Val = f(p.item.Val)
+	}
+	q.Lsn, q.Rsn, q.Dad = clone(p.Lsn, f), clone(p.Rsn, f), null
+	if q.Lsn != null {
+		q.Lsn.Dad = q
+	}
+	if q.Rsn != null {
+		q.Rsn.Dad = q
+	}
+	return q
+}
+
+// 在O(n)时间内复制整个树堆，保持原有的形状与优先级，懒惰标记树堆暂存的更新也一并复制；
+// f不为nil时用于复制值，否则新树堆与原树堆共享值
+func (this *Treap) Clone(f func(typeC) typeC) *Treap {
+	p := NewTreap()
+	p.aux = this.aux
+	p.root = clone(this.root, f)
+	return p
+}
+
+// 同Treap.Clone，返回二叉搜索树
+func (this *BST) Clone(f func(typeC) typeC) *BST {
+	return &BST{*this.Treap.Clone(f)}
+}
+
+// 同Treap.Clone，返回优先级队列
+func (this *PQ) Clone(f func(typeC) typeC) *PQ {
+	return &PQ{*this.Treap.Clone(f)}
+}
+
+// 删除全部键值对
+func (this *Treap) Clear() {
+	this.root = null
+	this.mod++
+}
+
+// 判断两个树堆是否按键的顺序包含相同的键、优先级和值，与树堆的形状无关；eq为nil时不比较值
+func (this *Treap) Equal(other *Treap, eq func(a, b typeC) bool) bool {
+	this.settle(this.root)
+	other.settle(other.root)
+	x, y := inorder(this.root, nil), inorder(other.root, nil)
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if compare(&x[i].item.Key, &y[i].item.Key) != 0 || x[i].wgt != y[i].wgt {
+			return false
+		}
+		if eq != nil && !eq(x[i].item.Val, y[i].item.Val) {
+			return false
+		}
+	}
+	return true
+}
+
+// 判断两棵二叉搜索树是否按键的顺序包含相同的键值对，不比较随机生成的优先级；eq为nil时只比较键
+func (this *BST) Equal(other *BST, eq func(a, b typeC) bool) bool {
+	this.settle(this.root)
+	other.settle(other.root)
+	x, y := inorder(this.root, nil), inorder(other.root, nil)
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if compare(&x[i].item.Key, &y[i].item.Key) != 0 {
+			return false
+		}
+		if eq != nil && !eq(x[i].item.Val, y[i].item.Val) {
+			return false
+		}
+	}
+	return true
+}
+
+// 判断两个优先级队列是否包含相同的任务，即按优先级排列后优先级与值都相同，
+// 优先级相同的任务之间不考虑顺序，不比较随机生成的键；eq为nil时只比较优先级
+func (this *PQ) Equal(other *PQ, eq func(a, b typeC) bool) bool {
+	x, y := inorder(this.root, nil), inorder(other.root, nil)
+	if len(x) != len(y) {
+		return false
+	}
+	sort.SliceStable(x, func(i, j int) bool { return x[i].wgt < x[j].wgt })
+	sort.SliceStable(y, func(i, j int) bool { return y[i].wgt < y[j].wgt })
+	for i := 0; i < len(x); {
+		j := i + 1
+		for j < len(x) && x[j].wgt == x[i].wgt {
+			j++
+		}
+		if y[i].wgt != x[i].wgt || (j < len(y) && y[j].wgt == x[i].wgt) || y[j-1].wgt != x[i].wgt {
+			return false
+		}
+		if eq != nil && !match(x[i:j], y[i:j], eq) {
+			return false
+		}
+		i = j
+	}
+	return true
+}
+
+// 判断两组节点的值能否按eq一一配对
+func match(x, y []*Node, eq func(a, b typeC) bool) bool {
+	used := make([]bool, len(y))
+outer:
+	for _, p := range x {
+		for k, q := range y {
+			if !used[k] && eq(p.item.Val, q.item.Val) {
+				used[k] = true
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package treap
+
+// 子树中键最小的节点
+func leftmost(p *Node) *Node {
+	for p.Lsn != null {
+		p = p.Lsn
+	}
+	return p
+}
+
+// 子树中键最大的节点
+func rightmost(p *Node) *Node {
+	for p.Rsn != null {
+		p = p.Rsn
+	}
+	return p
+}
+
+// 借助父节点指针获得中序的后继节点，没有时返回nil
+func successor(p *Node) *Node {
+	if p.Rsn != null {
+		return leftmost(p.Rsn)
+	}
+	for q := p.Dad; q != null; p, q = q, q.Dad {
+		if q.Lsn == p {
+			return q
+		}
+	}
+	return nil
+}
+
+// 借助父节点指针获得中序的前驱节点，没有时返回nil
+func predecessor(p *Node) *Node {
+	if p.Lsn != null {
+		return rightmost(p.Lsn)
+	}
+	for q := p.Dad; q != null; p, q = q, q.Dad {
+		if q.Rsn == p {
+			return q
+		}
+	}
+	return nil
+}
+
+// 返回第一个键不小于k的节点，没有时返回nil
+func (this *Treap) ceil(k *Key) *Node {
+	var q *Node
+	for p := this.root; p != null; {
+		if compare(&p.item.Key, k) >= 0 {
+			q, p = p, p.Lsn
+		} else {
+			p = p.Rsn
+		}
+	}
+	return q
+}
+
+// 树堆的游标，按键的顺序在节点间移动，或者处于无效位置
+type Cursor struct {
+	tree *Treap
+	node *Node
+	mod  uint64 // 定位时容器的修改次数
+}
+
+// 创建本树堆的游标，初始处于无效位置
+func (this *Treap) Cursor() *Cursor {
+	return &Cursor{tree: this}
+}
+
+// 移动到第一个键不小于(n, s)的节点，返回游标是否有效
+func (this *Cursor) Seek(n typeA, s typeB) bool {
+	this.mod = this.tree.mod
+	this.node = this.tree.ceil(&Key{n, s})
+	return this.node != nil
+}
+
+// 移动到最小键的节点，返回游标是否有效
+func (this *Cursor) SeekFirst() bool {
+	this.mod = this.tree.mod
+	this.node = nil
+	if p := this.tree.root; p != null {
+		this.node = leftmost(p)
+	}
+	return this.node != nil
+}
+
+// 移动到最大键的节点，返回游标是否有效
+func (this *Cursor) SeekLast() bool {
+	this.mod = this.tree.mod
+	this.node = nil
+	if p := this.tree.root; p != null {
+		this.node = rightmost(p)
+	}
+	return this.node != nil
+}
+
+// 移动到后继节点，返回游标是否有效
+func (this *Cursor) Next() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = successor(this.node)
+	}
+	return this.node != nil
+}
+
+// 移动到前驱节点，返回游标是否有效
+func (this *Cursor) Prev() bool {
+	if this.node != nil {
+		this.sync()
+		this.node = predecessor(this.node)
+	}
+	return this.node != nil
+}
+
+// 游标是否指向树堆中的节点
+func (this *Cursor) Valid() bool {
+	return this.node != nil
+}
+
+// 返回当前节点的键，游标无效时panic
+func (this *Cursor) Key() (typeA, typeB) {
+	this.sync()
+	return this.node.Key()
+}
+
+// 返回当前节点的值，懒惰标记树堆中会先下传路径上暂存的更新；游标无效时panic
+func (this *Cursor) Value() typeC {
+	this.sync()
+	this.tree.expose(this.node)
+	return this.node.item.Val
+}
+
+// 设置当前节点的值，懒惰标记树堆中会同时更新聚合值；游标无效时panic
+func (this *Cursor) SetValue(v typeC) {
+	this.sync()
+	t := this.tree
+	if debug {
+		defer t.check()
+	}
+	t.expose(this.node)
+	this.node.item.Val = v
+	t.repair(this.node)
+}
+
+// 删除当前节点并移动到其后继节点，返回游标是否有效；游标无效时什么也不做
+func (this *Cursor) Delete() bool {
+	p := this.node
+	if p == nil {
+		return false
+	}
+	this.sync()
+	t := this.tree
+	if debug {
+		defer t.check()
+	}
+	this.node = successor(p)
+	t.expose(p)
+	d := p.Dad
+	if r := t.release(p); d == null {
+		t.root = r
+	}
+	t.mod++
+	this.mod = t.mod
+	return this.node != nil
+}
+
+// 确认游标定位以来容器没有被游标以外的途径插入或删除过节点，否则panic
+func (this *Cursor) sync() {
+	if this.mod != this.tree.mod {
+		panic(ErrModified)
+	}
+}
@@ -0,0 +1,83 @@
+package treap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/hydra13142/container"
+)
+
+// 未提供标签函数时使用的节点标签
+func keyLabel(p *Node) string {
+	return fmt.Sprintf("%d, %s", p.item.Key.N, p.item.Key.S)
+}
+
+// 按中序收集子树中的节点
+func inorder(t *Node, v []*Node) []*Node {
+	if t == null {
+		return v
+	}
+	v = inorder(t.Lsn, v)
+	v = append(v, t)
+	return inorder(t.Rsn, v)
+}
+
+// 按中序为每个节点编号，用于生成图中节点的名称
+func (this *Treap) number() ([]*Node, map[interface{}]int) {
+	v := inorder(this.root, nil)
+	return v, container.Number(len(v), func(i int) interface{} { return v[i] })
+}
+
+// 以Graphviz的DOT格式输出树堆的结构，f生成节点的标签，为nil时使用键。
+// 实线为子节点，点线指向父节点，节点旁标注其优先级。
+func (this *Treap) WriteDOT(w io.Writer, f func(*Node) string) error {
+	if f == nil {
+		f = keyLabel
+	}
+	v, m := this.number()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph treap {\n\tnode [shape=box];\n")
+	for i, p := range v {
+		fmt.Fprintf(bw, "\tn%d [label=\"%s\", xlabel=\"w=%d\"];\n", i, container.DotEscape(f(p)), p.wgt)
+	}
+	for i, p := range v {
+		if p.Lsn != null {
+			fmt.Fprintf(bw, "\tn%d -> n%d [label=\"L\"];\n", i, m[p.Lsn])
+		}
+		if p.Rsn != null {
+			fmt.Fprintf(bw, "\tn%d -> n%d [label=\"R\"];\n", i, m[p.Rsn])
+		}
+		if p.Dad != null {
+			fmt.Fprintf(bw, "\tn%d -> n%d [style=dotted, color=blue, constraint=false];\n", i, m[p.Dad])
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// 以Mermaid流程图的格式输出树堆的结构，f生成节点的标签，为nil时使用键。
+// 实线为子节点，虚线指向父节点，节点标签后附其优先级。
+func (this *Treap) WriteMermaid(w io.Writer, f func(*Node) string) error {
+	if f == nil {
+		f = keyLabel
+	}
+	v, m := this.number()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "flowchart TD\n")
+	for i, p := range v {
+		fmt.Fprintf(bw, "\tn%d[\"%s<br/>w=%d\"]\n", i, container.MermaidEscape(f(p)), p.wgt)
+	}
+	for i, p := range v {
+		if p.Lsn != null {
+			fmt.Fprintf(bw, "\tn%d -->|L| n%d\n", i, m[p.Lsn])
+		}
+		if p.Rsn != null {
+			fmt.Fprintf(bw, "\tn%d -->|R| n%d\n", i, m[p.Rsn])
+		}
+		if p.Dad != null {
+			fmt.Fprintf(bw, "\tn%d -.->|parent| n%d\n", i, m[p.Dad])
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,147 @@
+package treap
+
+// 支持区间更新与区间聚合的树堆所需的运算，更新以懒惰标记的形式暂存在子树的根节点上
+type Lazy struct {
+	Combine func(a, b typeD) typeD              // 按键的顺序合并两个聚合值，须满足结合律
+	Lift    func(v typeC) typeD                 // 将单个值映射为聚合值
+	Update  func(t typeE, v typeC) typeC        // 将更新t作用于单个值
+	Apply   func(t typeE, a typeD, n int) typeD // 将更新t作用于n个值的聚合值，结果应与逐个更新后再聚合相同
+	Compose func(s, t typeE) typeE              // 合并两个更新，结果相当于先作用t再作用s
+}
+
+// 懒惰标记树堆中节点的附加信息
+type augment struct {
+	cnt int   // 子树中节点的数目
+	agg typeD // 子树中各值的聚合值，已包含本节点上的更新
+	tag typeE // 尚未下传给子节点的更新
+	lzy bool  // tag是否有效
+}
+
+// 创建一个支持区间更新与区间聚合的二叉搜索树
+func NewLazyBST(l Lazy) *BST {
+	p := NewBST()
+	p.aux = &l
+	return p
+}
+
+// 将更新t作用于以p为根的子树：本节点的值与聚合值立即更新，对子节点的更新暂存为标记
+func (this *Treap) mark(p *Node, t typeE) {
+	p.item.Val = this.aux.Update(t, p.item.Val)
+	p.agg = this.aux.Apply(t, p.agg, p.cnt)
+	if p.lzy {
+		p.tag = this.aux.Compose(t, p.tag)
+	} else {
+		p.tag, p.lzy = t, true
+	}
+}
+
+// 将节点上暂存的更新下传给子节点，非懒惰标记树堆中什么也不做
+func (this *Treap) push(p *Node) {
+	if this.aux == nil || !p.lzy {
+		return
+	}
+	if p.Lsn != null {
+		this.mark(p.Lsn, p.tag)
+	}
+	if p.Rsn != null {
+		this.mark(p.Rsn, p.tag)
+	}
+	p.tag, p.lzy = nil, false
+}
+
+// 由子节点重新计算节点的大小和聚合值，非懒惰标记树堆中什么也不做
+func (this *Treap) pull(p *Node) {
+	if this.aux == nil {
+		return
+	}
+	p.cnt = p.Lsn.cnt + p.Rsn.cnt + 1
+	v := this.aux.Lift(p.item.Val)
+	if p.Lsn != null {
+		v = this.aux.Combine(p.Lsn.agg, v)
+	}
+	if p.Rsn != null {
+		v = this.aux.Combine(v, p.Rsn.agg)
+	}
+	p.agg = v
+}
+
+// 从节点开始沿父节点指针更新到根节点为止的大小和聚合值
+func (this *Treap) repair(p *Node) {
+	if this.aux == nil {
+		return
+	}
+	for ; p != null; p = p.Dad {
+		this.pull(p)
+	}
+}
+
+// 将子树中所有暂存的更新下传到底，之后各节点的值都是最新的
+func (this *Treap) settle(p *Node) {
+	if this.aux == nil || p == null {
+		return
+	}
+	this.push(p)
+	this.settle(p.Lsn)
+	this.settle(p.Rsn)
+}
+
+// 按后序重新计算整棵子树的大小和聚合值，要求子树中没有暂存的更新
+func (this *Treap) refresh(p *Node) {
+	if this.aux == nil || p == null {
+		return
+	}
+	this.refresh(p.Lsn)
+	this.refresh(p.Rsn)
+	this.pull(p)
+}
+
+// 对键在[lo, hi)范围内的所有值作用更新t，期望耗时O(log n)；非懒惰标记树堆中什么也不做。
+// 范围不包含hi，与DeleteRange一致；要包含键为(n, s)的上界，可将hi取为Key{n, s + "\x00"}
+func (this *BST) Apply(lo, hi Key, t typeE) {
+	if debug {
+		defer this.check()
+	}
+	if this.aux == nil || compare(&lo, &hi) >= 0 {
+		return
+	}
+	l, x := this.split(this.root, &lo)
+	m, r := this.split(x, &hi)
+	if m != null {
+		this.mark(m, t)
+	}
+	if this.root = this.join(this.join(l, m), r); this.root != null {
+		this.root.Dad = null
+	}
+}
+
+// 返回键在[lo, hi)范围内所有值按键的顺序合并的聚合值，期望耗时O(log n)。
+// 范围内没有键值对，或者本树不是由NewLazyBST创建的，ok为false。范围的含义同Apply。
+// 本方法会分割再合并树堆，因此不能与其他操作同时进行
+func (this *BST) Aggregate(lo, hi Key) (v typeD, ok bool) {
+	if this.aux == nil || compare(&lo, &hi) >= 0 {
+		return nil, false
+	}
+	l, x := this.split(this.root, &lo)
+	m, r := this.split(x, &hi)
+	if m != null {
+		v, ok = m.agg, true
+	}
+	if this.root = this.join(this.join(l, m), r); this.root != null {
+		this.root.Dad = null
+	}
+	return v, ok
+}
+
+// 自根节点向下将路径上暂存的更新下传到节点p，之后p的值是最新的
+func (this *Treap) expose(p *Node) {
+	if this.aux == nil {
+		return
+	}
+	var v []*Node
+	for q := p.Dad; q != null; q = q.Dad {
+		v = append(v, q)
+	}
+	for i := len(v) - 1; i >= 0; i-- {
+		this.push(v[i])
+	}
+}
@@ -0,0 +1,125 @@
+package treap
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// 仿射更新x → a*x + b，两个更新的合并不满足交换律
+type affine struct {
+	a, b int64
+}
+
+// 聚合值：和以及按键的顺序的首个、末个值，合并不满足交换律
+type summary struct {
+	sum, first, last int64
+}
+
+var affineSum = Lazy{
+	Combine: func(a, b typeD) typeD {
+		x, y := a.(summary), b.(summary)
+		return summary{x.sum + y.sum, x.first, y.last}
+	},
+	Lift: func(v typeC) typeD {
+		x := v.(int64)
+		return summary{x, x, x}
+	},
+	Update: func(t typeE, v typeC) typeC {
+		f := t.(affine)
+		return f.a*v.(int64) + f.b
+	},
+	Apply: func(t typeE, a typeD, n int) typeD {
+		f, x := t.(affine), a.(summary)
+		return summary{f.a*x.sum + f.b*int64(n), f.a*x.first + f.b, f.a*x.last + f.b}
+	},
+	Compose: func(s, t typeE) typeE {
+		f, g := s.(affine), t.(affine)
+		return affine{f.a * g.a, f.a*g.b + f.b}
+	},
+}
+
+// 以有序的键和值的映射作为模型检查懒惰标记树堆的区间更新与区间聚合
+func TestLazy(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	tr := NewLazyBST(affineSum)
+	ref := map[int64]int64{}
+	// 模型中键在[lo, hi)内的值按键的顺序构成的序列
+	span := func(lo, hi int64) []int64 {
+		var ks []int64
+		for k := range ref {
+			if k >= lo && k < hi {
+				ks = append(ks, k)
+			}
+		}
+		sort.Slice(ks, func(i, j int) bool { return ks[i] < ks[j] })
+		v := make([]int64, len(ks))
+		for i, k := range ks {
+			v[i] = ref[k]
+		}
+		return v
+	}
+	for it := 0; it < 5000; it++ {
+		k := rd.Int63n(60)
+		lo, hi := rd.Int63n(70)-5, rd.Int63n(70)-5
+		switch rd.Intn(6) {
+		case 0, 1:
+			v := rd.Int63n(100)
+			tr.Update(k, "", v)
+			ref[k] = v
+		case 2:
+			_, ok := ref[k]
+			if _, found := tr.Delete(k, ""); found != ok {
+				t.Fatalf("Delete(%d) = %v; want %v", k, found, ok)
+			}
+			delete(ref, k)
+		case 3:
+			f := affine{rd.Int63n(3) - 1, rd.Int63n(7) - 3}
+			tr.Apply(Key{lo, ""}, Key{hi, ""}, f)
+			for x := range ref {
+				if x >= lo && x < hi {
+					ref[x] = f.a*ref[x] + f.b
+				}
+			}
+		case 4:
+			v := tr.Search(k, "")
+			if x, ok := ref[k]; ok && v != x || !ok && v != nil {
+				t.Fatalf("Search(%d) = %v; want %v", k, v, ref[k])
+			}
+		case 5:
+			if n := tr.DeleteRange(Key{lo, ""}, Key{hi, ""}); n != len(span(lo, hi)) {
+				t.Fatalf("DeleteRange(%d, %d) = %d; want %d", lo, hi, n, len(span(lo, hi)))
+			}
+			for x := range ref {
+				if x >= lo && x < hi {
+					delete(ref, x)
+				}
+			}
+		}
+		if err := tr.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		v := span(lo, hi)
+		a, ok := tr.Aggregate(Key{lo, ""}, Key{hi, ""})
+		if ok != (len(v) > 0) {
+			t.Fatalf("Aggregate(%d, %d) ok = %v with %d values in range", lo, hi, ok, len(v))
+		}
+		if ok {
+			want := summary{0, v[0], v[len(v)-1]}
+			for _, x := range v {
+				want.sum += x
+			}
+			if a != want {
+				t.Fatalf("Aggregate(%d, %d) = %v; want %v", lo, hi, a, want)
+			}
+		}
+	}
+	// 游标读到的值已经作用了全部更新
+	c := tr.Cursor()
+	for ok := c.SeekFirst(); ok; ok = c.Next() {
+		n, _ := c.Key()
+		if c.Value() != ref[n] {
+			t.Fatalf("cursor value at %d is %v; want %d", n, c.Value(), ref[n])
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package treap
+
+import "errors"
+
+// 游标或迭代回调执行期间树堆被其他途径插入或删除了节点时，以此为参数panic
+// 只有游标和接受回调的方法会检查，调用者直接沿Lsn、Rsn、Dad指针遍历节点时不检查，须自行避免修改
+var ErrModified = errors.New("treap: 迭代期间树堆被修改")
+
+// 包装回调函数f，f返回后若树堆的节点被插入或删除过则panic
+func (this *Treap) guard(f func(*Node) bool) func(*Node) bool {
+	m := this.mod
+	return func(p *Node) bool {
+		r := f(p)
+		if this.mod != m {
+			panic(ErrModified)
+		}
+		return r
+	}
+}
@@ -0,0 +1,39 @@
+package treap
+
+// 返回键在[lo, hi)范围内优先级最小（最优先）的节点，范围内没有节点时返回nil。
+// 由于堆序，查找路径上第一个落在范围内的节点就是所求，期望耗时O(log n)。
+func (this *Treap) MinWeightInRange(lo, hi Key) *Node {
+	for p := this.root; p != null; {
+		this.push(p)
+		switch {
+		case compare(&p.item.Key, &lo) < 0:
+			p = p.Rsn
+		case compare(&p.item.Key, &hi) >= 0:
+			p = p.Lsn
+		default:
+			return p
+		}
+	}
+	return nil
+}
+
+// 按键的顺序对子树中键在[lo, hi)范围内且优先级小于w的节点调用f，f返回false时停止并返回false
+func (this *Treap) below(p *Node, lo, hi *Key, w int64, f func(*Node) bool) bool {
+	if p == null || p.wgt >= w {
+		return true
+	}
+	this.push(p)
+	if compare(&p.item.Key, lo) < 0 {
+		return this.below(p.Rsn, lo, hi, w, f)
+	}
+	if compare(&p.item.Key, hi) >= 0 {
+		return this.below(p.Lsn, lo, hi, w, f)
+	}
+	return this.below(p.Lsn, lo, hi, w, f) && f(p) && this.below(p.Rsn, lo, hi, w, f)
+}
+
+// 按键的顺序对键在[lo, hi)范围内且优先级小于w的所有节点调用f，f返回false时停止，其间修改本树堆会引发panic。
+// 优先级不小于w的子树整个被跳过，期望耗时O(log n + m)，m为结果的数目
+func (this *Treap) RangeBelowWeight(lo, hi Key, w int64, f func(*Node) bool) {
+	this.below(this.root, &lo, &hi, w, this.guard(f))
+}
@@ -0,0 +1,74 @@
+package treap
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 以键到优先级的映射作为模型检查三边查询：键在[lo, hi)内、优先级最小或小于w的节点
+func TestPriority(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	tr := NewTreap()
+	ref := map[int64]int64{}
+	for it := 0; it < 3000; it++ {
+		k := rd.Int63n(200)
+		if _, ok := ref[k]; ok && rd.Intn(2) == 0 {
+			tr.DeleteRange(Key{k, ""}, Key{k + 1, ""})
+			delete(ref, k)
+		} else if !ok {
+			// 优先级的取值很少，以产生大量相同的优先级
+			w := rd.Int63n(30)
+			tr.Update(w, k, "", k)
+			ref[k] = w
+		}
+		if err := tr.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		lo, hi, w := rd.Int63n(220)-10, rd.Int63n(220)-10, rd.Int63n(32)
+		var (
+			keys []int64
+			best int64 = -1
+		)
+		for x, y := range ref {
+			if x < lo || x >= hi {
+				continue
+			}
+			if best < 0 || y < best {
+				best = y
+			}
+			if y < w {
+				keys = append(keys, x)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		p := tr.MinWeightInRange(Key{lo, ""}, Key{hi, ""})
+		switch {
+		case best < 0 && p != nil:
+			t.Fatalf("MinWeightInRange(%d, %d) = %d; want nil", lo, hi, p.item.Key.N)
+		case best >= 0 && p == nil:
+			t.Fatalf("MinWeightInRange(%d, %d) = nil; want weight %d", lo, hi, best)
+		case p != nil && (p.Weight() != best || p.item.Key.N < lo || p.item.Key.N >= hi):
+			t.Fatalf("MinWeightInRange(%d, %d) = (%d, weight %d); want weight %d", lo, hi, p.item.Key.N, p.Weight(), best)
+		}
+		var got []int64
+		tr.RangeBelowWeight(Key{lo, ""}, Key{hi, ""}, w, func(p *Node) bool {
+			got = append(got, p.item.Key.N)
+			return true
+		})
+		if len(got) != len(keys) || len(keys) > 0 && !reflect.DeepEqual(got, keys) {
+			t.Fatalf("RangeBelowWeight(%d, %d, %d) = %v; want %v", lo, hi, w, got, keys)
+		}
+		if len(keys) > 1 {
+			got = got[:0]
+			tr.RangeBelowWeight(Key{lo, ""}, Key{hi, ""}, w, func(p *Node) bool {
+				got = append(got, p.item.Key.N)
+				return false
+			})
+			if len(got) != 1 || got[0] != keys[0] {
+				t.Fatalf("RangeBelowWeight(%d, %d, %d) did not stop after %v", lo, hi, w, keys[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package treap
+
+// 将以t为根的树堆分割为键小于k和键不小于k的两个树堆，两者根节点的父节点均为null
+func (this *Treap) split(t *Node, k *Key) (*Node, *Node) {
+	if t == null {
+		return null, null
+	}
+	this.push(t)
+	t.Dad = null
+	if compare(&t.item.Key, k) < 0 {
+		a, b := this.split(t.Rsn, k)
+		if t.Rsn = a; a != null {
+			a.Dad = t
+		}
+		this.pull(t)
+		return t, b
+	}
+	a, b := this.split(t.Lsn, k)
+	if t.Lsn = b; b != null {
+		b.Dad = t
+	}
+	this.pull(t)
+	return a, t
+}
+
+// 合并两个树堆并返回根节点，要求l的键均不大于r的键，根节点的父节点为null
+func (this *Treap) join(l, r *Node) *Node {
+	switch {
+	case l == null:
+		return r
+	case r == null:
+		return l
+	case l.wgt <= r.wgt:
+		this.push(l)
+		c := this.join(l.Rsn, r)
+		l.Rsn, c.Dad = c, l
+		this.pull(l)
+		return l
+	default:
+		this.push(r)
+		c := this.join(l, r.Lsn)
+		r.Lsn, c.Dad = c, r
+		this.pull(r)
+		return r
+	}
+}
+
+// 统计子树的节点数目
+func count(t *Node) int {
+	if t == null {
+		return 0
+	}
+	return count(t.Lsn) + count(t.Rsn) + 1
+}
+
+// 按键的升序收集子树中的节点，f返回true的节点计入删除数目，其余追加到v中
+func filter(t *Node, f func(*Node) bool, v []*Node, n int) ([]*Node, int) {
+	if t == null {
+		return v, n
+	}
+	v, n = filter(t.Lsn, f, v, n)
+	if f(t) {
+		n++
+	} else {
+		v = append(v, t)
+	}
+	return filter(t.Rsn, f, v, n)
+}
+
+// 删除键在[lo, hi)范围内的所有键值对，返回删除的数目。
+// 通过两次分割和一次合并完成，除统计数目外期望耗时为O(log n)。
+func (this *Treap) DeleteRange(lo, hi Key) int {
+	if debug {
+		defer this.check()
+	}
+	if compare(&lo, &hi) >= 0 {
+		return 0
+	}
+	l, x := this.split(this.root, &lo)
+	m, r := this.split(x, &hi)
+	if this.root = this.join(l, r); this.root != null {
+		this.root.Dad = null
+	}
+	n := count(m)
+	if n > 0 {
+		this.mod++
+	}
+	return n
+}
+
+// 删除所有使f返回true的键值对，返回删除的数目。
+// f按键的升序被调用，其间修改本树堆会引发panic；剩余的节点保留原有的优先级重建为树堆。
+func (this *Treap) DeleteFunc(f func(*Node) bool) int {
+	if debug {
+		defer this.check()
+	}
+	this.settle(this.root)
+	v, n := filter(this.root, this.guard(f), nil, 0)
+	if n > 0 {
+		this.root = build(v)
+		this.refresh(this.root)
+		this.mod++
+	}
+	return n
+}
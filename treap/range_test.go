@@ -0,0 +1,184 @@
+package treap
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// 删除之后剩余的节点必须保留原有的优先级，树堆仍满足堆序
+func TestTreapDelete(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	tr := NewTreap()
+	wgt := map[int64]int64{}
+	for i := int64(0); i < 300; i++ {
+		w := rd.Int63n(1000)
+		tr.Update(w, i, "", i)
+		wgt[i] = w
+	}
+	check := func(want int) {
+		t.Helper()
+		if err := tr.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		v := inorder(tr.root, nil)
+		if len(v) != want {
+			t.Fatalf("%d nodes left; want %d", len(v), want)
+		}
+		for _, p := range v {
+			n, _ := p.Key()
+			w, ok := wgt[n]
+			if !ok {
+				t.Fatalf("deleted key %d is still in the treap", n)
+			}
+			if p.Weight() != w || p.Val() != n {
+				t.Fatalf("node %d has weight %d and value %v; want %d and %d", n, p.Weight(), p.Val(), w, n)
+			}
+		}
+	}
+	if n := tr.DeleteRange(Key{50, ""}, Key{120, ""}); n != 70 {
+		t.Fatalf("DeleteRange(50, 120) = %d; want 70", n)
+	}
+	for i := int64(50); i < 120; i++ {
+		delete(wgt, i)
+	}
+	check(230)
+	// 删除优先级最小的根节点以及所有优先级小于100的节点
+	root := tr.root
+	n := tr.DeleteFunc(func(p *Node) bool { return p.Weight() < 100 || p == root })
+	for k, w := range wgt {
+		if w < 100 || k == root.N {
+			delete(wgt, k)
+		}
+	}
+	if n != 230-len(wgt) {
+		t.Fatalf("DeleteFunc = %d; want %d", n, 230-len(wgt))
+	}
+	check(len(wgt))
+	if n := tr.DeleteFunc(func(p *Node) bool { return true }); n != len(wgt) || tr.root != null {
+		t.Fatalf("deleting everything removed %d of %d nodes", n, len(wgt))
+	}
+	tr.Update(5, 1, "", int64(1))
+	wgt = map[int64]int64{1: 5}
+	check(1)
+}
+
+// 优先级队列中任务的优先级是树堆的优先级，键是随机生成的，DeleteRange按键删除的是随机的一部分任务
+func TestPQDelete(t *testing.T) {
+	type task struct {
+		w int64
+		v int
+		k Key
+	}
+	// keys为全部任务按升序排列的键，top为删除之前的队首
+	cases := []struct {
+		name string
+		del  func(q *PQ, keys []Key) int
+		keep func(x task, keys []Key, top *Node) bool
+	}{
+		{
+			"odd values",
+			func(q *PQ, keys []Key) int { return q.DeleteFunc(func(p *Node) bool { return p.Val().(int)%2 == 1 }) },
+			func(x task, keys []Key, top *Node) bool { return x.v%2 == 0 },
+		},
+		{
+			"key range",
+			func(q *PQ, keys []Key) int { return q.DeleteRange(keys[50], keys[120]) },
+			func(x task, keys []Key, top *Node) bool {
+				return compare(&x.k, &keys[50]) < 0 || compare(&x.k, &keys[120]) >= 0
+			},
+		},
+		{
+			"top",
+			func(q *PQ, keys []Key) int {
+				top := q.Peek()
+				return q.DeleteFunc(func(p *Node) bool { return p == top })
+			},
+			func(x task, keys []Key, top *Node) bool { return x.k != top.item.Key },
+		},
+		{
+			"everything",
+			func(q *PQ, keys []Key) int { return q.DeleteFunc(func(p *Node) bool { return true }) },
+			func(x task, keys []Key, top *Node) bool { return false },
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rd := rand.New(rand.NewSource(2))
+			q := NewPQ()
+			var (
+				all  []task
+				keys []Key
+			)
+			for i := 0; i < 200; i++ {
+				x := task{w: rd.Int63n(50), v: i}
+				x.k = q.Push(x.w, x.v).item.Key
+				all, keys = append(all, x), append(keys, x.k)
+			}
+			sort.Slice(keys, func(i, j int) bool { return compare(&keys[i], &keys[j]) < 0 })
+			top := q.Peek()
+			var want []task
+			for _, x := range all {
+				if c.keep(x, keys, top) {
+					want = append(want, x)
+				}
+			}
+			if n := c.del(q, keys); n != 200-len(want) {
+				t.Fatalf("deleted %d tasks; want %d", n, 200-len(want))
+			}
+			if err := q.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			// 出队的顺序按优先级排列，相同优先级的任务之间不规定顺序
+			var got []task
+			for p := q.Pop(); p != nil; p = q.Pop() {
+				got = append(got, task{p.Weight(), p.Val().(int), p.item.Key})
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].w < got[i-1].w {
+					t.Fatalf("task %v was popped after %v", got[i], got[i-1])
+				}
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].v < got[j].v })
+			if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+				t.Fatalf("popped %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBSTDeleteRange(t *testing.T) {
+	rd := rand.New(rand.NewSource(3))
+	for it := 0; it < 100; it++ {
+		tr := NewBST()
+		var ref []Key
+		for i, n := 0, rd.Intn(200); i < n; i++ {
+			k := Key{rd.Int63n(40), string(rune('a' + rd.Intn(3)))}
+			tr.Insert(k.N, k.S, nil)
+			ref = append(ref, k)
+		}
+		sort.Slice(ref, func(i, j int) bool { return compare(&ref[i], &ref[j]) < 0 })
+		lo := Key{rd.Int63n(44) - 2, string(rune('a' + rd.Intn(3)))}
+		hi := Key{lo.N + rd.Int63n(20), string(rune('a' + rd.Intn(3)))}
+		var rest []Key
+		for _, k := range ref {
+			if compare(&k, &lo) < 0 || compare(&k, &hi) >= 0 {
+				rest = append(rest, k)
+			}
+		}
+		if n := tr.DeleteRange(lo, hi); n != len(ref)-len(rest) {
+			t.Fatalf("DeleteRange(%v, %v) = %d; want %d", lo, hi, n, len(ref)-len(rest))
+		}
+		if err := tr.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		var got []Key
+		for _, p := range inorder(tr.root, nil) {
+			got = append(got, p.item.Key)
+		}
+		if len(got) != len(rest) || len(rest) > 0 && !reflect.DeepEqual(got, rest) {
+			t.Fatalf("keys left %v; want %v", got, rest)
+		}
+	}
+}
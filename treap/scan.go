@@ -0,0 +1,30 @@
+package treap
+
+import "strings"
+
+// 按中序遍历子树中键不小于lo的节点，遇到第一个使ok返回false的节点即停止，f返回false时也停止。
+// 返回是否应当继续遍历；沿途下传暂存的更新，因此f得到的节点的值都是最新的
+func (this *Treap) scan(p *Node, lo *Key, ok func(*Key) bool, f func(*Node) bool) bool {
+	if p == null {
+		return true
+	}
+	this.push(p)
+	if compare(&p.item.Key, lo) < 0 {
+		return this.scan(p.Rsn, lo, ok, f)
+	}
+	return this.scan(p.Lsn, lo, ok, f) && ok(&p.item.Key) && f(p) && this.scan(p.Rsn, lo, ok, f)
+}
+
+// 按键的顺序对第一部分为n、第二部分以prefix为前缀的所有键值对调用f，f返回false时停止，其间修改本树堆会引发panic。
+// 跳过键小于(n, prefix)的子树，遇到第一个不匹配的键即停止，期望耗时O(log n + m)
+func (this *Treap) ScanPrefix(n typeA, prefix typeB, f func(*Node) bool) {
+	ok := func(k *Key) bool {
+		return k.N == n && strings.HasPrefix(k.S, prefix)
+	}
+	this.scan(this.root, &Key{n, prefix}, ok, this.guard(f))
+}
+
+// 按键的顺序对第一部分为n的所有键值对调用f，f返回false时停止，其间修改本树堆会引发panic
+func (this *Treap) ScanN(n typeA, f func(*Node) bool) {
+	this.ScanPrefix(n, "", f)
+}
@@ -0,0 +1,285 @@
+package treap
+
+import "fmt"
+
+// 隐式树堆的节点，以在序列中的位置为隐含的键
+type piece struct {
+	wgt int64
+	cnt int  // 子树中元素的数目
+	rev bool // 子树需要翻转，但尚未下传给子节点
+	val typeC
+	lsn *piece
+	rsn *piece
+}
+
+// 以隐式树堆为底层结构的序列，按位置插入、删除、截取、拼接和翻转的期望耗时均为O(log n)
+type Sequence struct {
+	root *piece
+	rnd  source // 用于生成优先级
+}
+
+// 子树中元素的数目，空子树为0
+func (this *piece) size() int {
+	if this == nil {
+		return 0
+	}
+	return this.cnt
+}
+
+// 将翻转标记下传给子节点
+func (this *piece) push() {
+	if this.rev {
+		this.lsn, this.rsn = this.rsn, this.lsn
+		if this.lsn != nil {
+			this.lsn.rev = !this.lsn.rev
+		}
+		if this.rsn != nil {
+			this.rsn.rev = !this.rsn.rev
+		}
+		this.rev = false
+	}
+}
+
+// 由子节点重新计算子树中元素的数目
+func (this *piece) pull() {
+	this.cnt = this.lsn.size() + this.rsn.size() + 1
+}
+
+// 将子树分割为前i个元素和其余元素两部分
+func cut(t *piece, i int) (*piece, *piece) {
+	if t == nil {
+		return nil, nil
+	}
+	t.push()
+	if n := t.lsn.size(); i <= n {
+		a, b := cut(t.lsn, i)
+		t.lsn = b
+		t.pull()
+		return a, t
+	} else {
+		a, b := cut(t.rsn, i-n-1)
+		t.rsn = a
+		t.pull()
+		return t, b
+	}
+}
+
+// 将两棵子树首尾相接，优先级小的节点在上
+func glue(l, r *piece) *piece {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.wgt <= r.wgt:
+		l.push()
+		l.rsn = glue(l.rsn, r)
+		l.pull()
+		return l
+	default:
+		r.push()
+		r.lsn = glue(l, r.lsn)
+		r.pull()
+		return r
+	}
+}
+
+// 按顺序将子树中的元素追加到v中
+func collect(t *piece, v []typeC) []typeC {
+	if t == nil {
+		return v
+	}
+	t.push()
+	v = collect(t.lsn, v)
+	v = append(v, t.val)
+	return collect(t.rsn, v)
+}
+
+// 按后序重新计算子树中元素的数目
+func recount(t *piece) {
+	if t != nil {
+		recount(t.lsn)
+		recount(t.rsn)
+		t.pull()
+	}
+}
+
+// 创建一个序列，以v为初始元素，耗时O(n)
+func NewSequence(v ...typeC) *Sequence {
+	s := new(Sequence)
+	st := make([]*piece, 0, 64)
+	for _, x := range v {
+		p := &piece{wgt: s.rnd.Int63(), val: x}
+		var l *piece
+		for len(st) > 0 && st[len(st)-1].wgt > p.wgt {
+			l = st[len(st)-1]
+			st = st[:len(st)-1]
+		}
+		p.lsn = l
+		if len(st) > 0 {
+			st[len(st)-1].rsn = p
+		}
+		st = append(st, p)
+	}
+	if len(st) > 0 {
+		s.root = st[0]
+		recount(s.root)
+	}
+	return s
+}
+
+// 返回序列中元素的数目
+func (this *Sequence) Len() int {
+	return this.root.size()
+}
+
+// 在位置i之前插入元素v，i等于Len()时追加到末尾；i越界时返回false，此时序列不变
+func (this *Sequence) InsertAt(i int, v typeC) bool {
+	if debug {
+		defer this.check()
+	}
+	if i < 0 || i > this.Len() {
+		return false
+	}
+	l, r := cut(this.root, i)
+	p := &piece{wgt: this.rnd.Int63(), cnt: 1, val: v}
+	this.root = glue(glue(l, p), r)
+	return true
+}
+
+// 删除位置i的元素并返回其值，i越界时ok为false
+func (this *Sequence) DeleteAt(i int) (v typeC, ok bool) {
+	if debug {
+		defer this.check()
+	}
+	if i < 0 || i >= this.Len() {
+		return nil, false
+	}
+	l, r := cut(this.root, i)
+	m, r := cut(r, 1)
+	this.root = glue(l, r)
+	return m.val, true
+}
+
+// 返回位置i的元素，i越界时ok为false
+func (this *Sequence) Get(i int) (v typeC, ok bool) {
+	if i < 0 || i >= this.Len() {
+		return nil, false
+	}
+	for p := this.root; ; {
+		p.push()
+		switch n := p.lsn.size(); {
+		case i < n:
+			p = p.lsn
+		case i > n:
+			i -= n + 1
+			p = p.rsn
+		default:
+			return p.val, true
+		}
+	}
+}
+
+// 设置位置i的元素，i越界时返回false
+func (this *Sequence) Set(i int, v typeC) bool {
+	if i < 0 || i >= this.Len() {
+		return false
+	}
+	for p := this.root; ; {
+		p.push()
+		switch n := p.lsn.size(); {
+		case i < n:
+			p = p.lsn
+		case i > n:
+			i -= n + 1
+			p = p.rsn
+		default:
+			p.val = v
+			return true
+		}
+	}
+}
+
+// 区间[i, j)是否在序列范围内
+func (this *Sequence) inside(i, j int) bool {
+	return i >= 0 && i <= j && j <= this.Len()
+}
+
+// 按顺序返回位置在[i, j)内的元素，区间越界时ok为false
+func (this *Sequence) Slice(i, j int) (v []typeC, ok bool) {
+	if !this.inside(i, j) {
+		return nil, false
+	}
+	l, r := cut(this.root, i)
+	m, r := cut(r, j-i)
+	v = collect(m, make([]typeC, 0, j-i))
+	this.root = glue(glue(l, m), r)
+	return v, true
+}
+
+// 将序列other的全部元素依次追加到本序列末尾，other随后变为空序列
+func (this *Sequence) Concat(other *Sequence) {
+	if debug {
+		defer this.check()
+	}
+	if other == this {
+		return
+	}
+	this.root = glue(this.root, other.root)
+	other.root = nil
+}
+
+// 将位置在[i, j)内的元素翻转，只在子树根节点上打标记；区间越界时返回false，此时序列不变
+func (this *Sequence) Reverse(i, j int) bool {
+	if debug {
+		defer this.check()
+	}
+	if !this.inside(i, j) {
+		return false
+	}
+	l, r := cut(this.root, i)
+	m, r := cut(r, j-i)
+	if m != nil {
+		m.rev = !m.rev
+	}
+	this.root = glue(glue(l, m), r)
+	return true
+}
+
+// 检查子树的堆序与元素数目，d为深度，用于发现环
+func (this *piece) verify(d int) error {
+	if this == nil {
+		return nil
+	}
+	if d > 1<<10 {
+		return fmt.Errorf("treap: 序列的深度超过了上限%d，可能存在环", 1<<10)
+	}
+	for _, c := range [2]*piece{this.lsn, this.rsn} {
+		if c == nil {
+			continue
+		}
+		if c.wgt < this.wgt {
+			return fmt.Errorf("treap: 序列中子节点的优先级%d小于父节点的优先级%d", c.wgt, this.wgt)
+		}
+		if err := c.verify(d + 1); err != nil {
+			return err
+		}
+	}
+	if n := this.lsn.size() + this.rsn.size() + 1; this.cnt != n {
+		return fmt.Errorf("treap: 序列中节点记录的元素数目为%d，实际为%d", this.cnt, n)
+	}
+	return nil
+}
+
+// 检查序列的各项不变式：优先级的堆序以及各节点记录的元素数目。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *Sequence) Verify() error {
+	return this.root.verify(1)
+}
+
+// 调试模式下，每次修改后检查不变式，不满足时立即panic
+func (this *Sequence) check() {
+	if err := this.Verify(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,108 @@
+package treap
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// 以切片作为模型检查Sequence的各项操作，每次修改后调用Verify
+func TestSequence(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	var ref []typeC
+	for i := 0; i < 50; i++ {
+		ref = append(ref, i)
+	}
+	s := NewSequence(ref...)
+	next := len(ref)
+	// 随机位置，有时越界
+	pos := func(n int) int {
+		return rd.Intn(n+5) - 2
+	}
+	for it := 0; it < 5000; it++ {
+		n := len(ref)
+		switch rd.Intn(7) {
+		case 0:
+			i := pos(n)
+			ok := s.InsertAt(i, next)
+			if want := i >= 0 && i <= n; ok != want {
+				t.Fatalf("InsertAt(%d) with %d elements = %v", i, n, ok)
+			} else if want {
+				ref = append(ref[:i], append([]typeC{next}, ref[i:]...)...)
+			}
+			next++
+		case 1:
+			i := pos(n)
+			v, ok := s.DeleteAt(i)
+			if want := i >= 0 && i < n; ok != want {
+				t.Fatalf("DeleteAt(%d) with %d elements = %v", i, n, ok)
+			} else if want {
+				if v != ref[i] {
+					t.Fatalf("DeleteAt(%d) = %v; want %v", i, v, ref[i])
+				}
+				ref = append(ref[:i], ref[i+1:]...)
+			}
+		case 2:
+			i, j := pos(n), pos(n)
+			ok := s.Reverse(i, j)
+			if want := i >= 0 && i <= j && j <= n; ok != want {
+				t.Fatalf("Reverse(%d, %d) with %d elements = %v", i, j, n, ok)
+			} else if want {
+				for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+					ref[a], ref[b] = ref[b], ref[a]
+				}
+			}
+		case 3:
+			i, j := pos(n), pos(n)
+			v, ok := s.Slice(i, j)
+			if want := i >= 0 && i <= j && j <= n; ok != want {
+				t.Fatalf("Slice(%d, %d) with %d elements = %v", i, j, n, ok)
+			} else if want && (len(v) != j-i || j > i && !reflect.DeepEqual(v, ref[i:j])) {
+				t.Fatalf("Slice(%d, %d) = %v; want %v", i, j, v, ref[i:j])
+			}
+		case 4:
+			i := pos(n)
+			ok := s.Set(i, -next)
+			if want := i >= 0 && i < n; ok != want {
+				t.Fatalf("Set(%d) with %d elements = %v", i, n, ok)
+			} else if want {
+				ref[i] = -next
+			}
+			next++
+		case 5:
+			// 在末尾拼接一个新的序列，之后other应当为空
+			var tail []typeC
+			for i := rd.Intn(4); i > 0; i-- {
+				tail = append(tail, next)
+				next++
+			}
+			o := NewSequence(tail...)
+			s.Concat(o)
+			if o.Len() != 0 {
+				t.Fatalf("Concat left %d elements in other", o.Len())
+			}
+			ref = append(ref, tail...)
+		case 6:
+			i := pos(n)
+			v, ok := s.Get(i)
+			if want := i >= 0 && i < n; ok != want || want && v != ref[i] {
+				t.Fatalf("Get(%d) = %v, %v", i, v, ok)
+			}
+		}
+		if err := s.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if s.Len() != len(ref) {
+			t.Fatalf("Len() = %d; want %d", s.Len(), len(ref))
+		}
+		if it%50 == 0 {
+			if v, _ := s.Slice(0, s.Len()); len(ref) > 0 && !reflect.DeepEqual(v, ref) {
+				t.Fatalf("sequence is %v; want %v", v, ref)
+			}
+		}
+	}
+	s.Concat(s)
+	if s.Len() != len(ref) {
+		t.Fatalf("Concat with itself changed the length to %d", s.Len())
+	}
+}
@@ -0,0 +1,46 @@
+package treap
+
+import "unsafe"
+
+// 旋转次数的累计
+type rotation struct {
+	single uint64
+}
+
+// 树堆的结构统计信息
+type Stats struct {
+	Count   int     // 键值对的数目
+	Height  int     // 树堆的高度，空树堆为0
+	AvgPath float64 // 平均查找路径长度，即各节点深度（根节点为1）的平均值
+	Memory  uintptr // 节点占用内存的估计值，不含键中的字符串和值引用的数据
+	Single  uint64  // 自创建以来累计的单旋转次数，包括插入时上浮和删除时下沉的旋转
+}
+
+// 累计子树中节点的数目、高度和深度之和
+func (this *Stats) walk(p *Node, d int, sum *int) {
+	if p == null {
+		return
+	}
+	this.Count++
+	if d > this.Height {
+		this.Height = d
+	}
+	*sum += d
+	this.walk(p.Lsn, d+1, sum)
+	this.walk(p.Rsn, d+1, sum)
+}
+
+// 返回树堆的结构统计信息，需要遍历整个树堆，耗时O(n)
+func (this *Treap) Stats() Stats {
+	var (
+		s   Stats
+		sum int
+	)
+	s.walk(this.root, 1, &sum)
+	if s.Count > 0 {
+		s.AvgPath = float64(sum) / float64(s.Count)
+	}
+	s.Memory = uintptr(s.Count) * unsafe.Sizeof(Node{})
+	s.Single = this.rot.single
+	return s
+}
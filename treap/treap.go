@@ -1,11 +1,21 @@
 package treap
 
-import (
-	"math/rand"
-	"time"
-)
+import "math/rand"
 
-var rd = rand.New(rand.NewSource(time.Now().Unix()))
+// 随机数发生器的状态（splitmix64），各容器分别持有，因此不同的容器可以在不同的goroutine中使用
+type source uint64
+
+// 返回一个非负的随机数，首次调用时由math/rand的全局随机数初始化状态
+func (this *source) Int63() int64 {
+	if *this == 0 {
+		*this = source(rand.Int63() | 1)
+	}
+	*this += 0x9e3779b97f4a7c15
+	z := uint64(*this)
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64((z ^ z>>31) >> 1)
+}
 
 type typeA = int64
 
@@ -13,6 +23,10 @@ type typeB = string
 
 type typeC = interface{}
 
+type typeD = interface{}
+
+type typeE = interface{}
+
 // 由typeA和typeB复合构成的键
 type Key struct {
 	N typeA
@@ -37,11 +51,16 @@ type Node struct {
 	wgt int64
 	item
 	treePointer
+	augment
 }
 
 // 树堆
 type Treap struct {
 	root *Node
+	rot  rotation
+	aux  *Lazy
+	mod  uint64 // 插入或删除节点的次数，用于发现迭代期间的修改
+	rnd  source // 用于生成优先级队列的键和二叉搜索树的优先级
 }
 
 // 使用树堆为底层结构的优先级队列
@@ -49,7 +68,9 @@ type PQ struct {
 	Treap
 }
 
-// 以树堆为底层结构的二叉搜索树
+// 以树堆为底层结构的二叉搜索树。
+// 由NewLazyBST创建时，Search、Aggregate等读操作也会下传懒惰标记或者分割再合并树堆，
+// 因此即使只有读操作，也不能由多个协程同时使用
 type BST struct {
 	Treap
 }
@@ -77,9 +98,10 @@ func (this *Node) Key() (typeA, typeB) {
 	return this.N, this.S
 }
 
-// 获得节点的值，采用函数避免误修改
+// 获得节点的值，采用函数避免误修改。
+// 懒惰标记树堆中祖先节点上可能还暂存着更新，此时返回的值是过时的，应改用BST.Search或Cursor.Value
 func (this *Node) Val() typeC {
-	return this.Val
+	return this.item.Val
 }
 
 // 获得节点的优先级，越小越优先
@@ -87,9 +109,9 @@ func (this *Node) Weight() int64 {
 	return this.wgt
 }
 
-// 设置节点的值
+// 设置节点的值，懒惰标记树堆中不会更新聚合值
 func (this *Node) Set(v typeC) {
-	this.Val = v
+	this.item.Val = v
 }
 
 // 创建一个树堆
@@ -99,17 +121,22 @@ func NewTreap() *Treap {
 	return p
 }
 
-// 针对新加入的叶节点，从底向上维护树堆，返回维护后的树堆根节点
-func arrange(p *Node) *Node {
+// 针对新加入的叶节点，从底向上维护树堆并更新沿途的聚合值，返回维护后的树堆根节点。
+// 要求路径上的节点都已下传过更新。
+func (this *Treap) arrange(p *Node) *Node {
 	for {
 		D, L, R := p.Dad, p.Lsn, p.Rsn
+		this.pull(p)
 		if L.wgt < R.wgt {
 			if L.wgt < p.wgt {
 				r := L.Rsn
+				this.rot.single++
 				p.Lsn, p.Dad, L.Rsn, L.Dad = r, L, p, D
 				if r != null {
 					r.Dad = p
 				}
+				this.pull(p)
+				this.pull(L)
 				if D == null {
 					return L
 				}
@@ -122,10 +149,13 @@ func arrange(p *Node) *Node {
 		} else {
 			if R.wgt < p.wgt {
 				l := R.Lsn
+				this.rot.single++
 				p.Rsn, p.Dad, R.Lsn, R.Dad = l, R, p, D
 				if l != null {
 					l.Dad = p
 				}
+				this.pull(p)
+				this.pull(R)
 				if D == null {
 					return R
 				}
@@ -143,13 +173,17 @@ func arrange(p *Node) *Node {
 	}
 }
 
-// 将当前节点视为根节点多次旋转到成为叶节点后删除，并返回新的根节点
-func release(p *Node) *Node {
+// 将当前节点视为根节点多次旋转到成为叶节点后删除，并返回新的根节点。
+// 旋转前先将更新下传给参与旋转的节点，删除后更新到整棵树根节点为止的聚合值。
+func (this *Treap) release(p *Node) *Node {
 	q := &Node{treePointer: treePointer{p, p, p.Dad}}
+	this.push(p)
 	D, L, R := q, p.Lsn, p.Rsn
 	for {
 		if L.wgt < R.wgt {
+			this.push(L)
 			r := L.Rsn
+			this.rot.single++
 			p.Lsn, p.Dad, L.Rsn, L.Dad = r, L, p, D
 			if r != null {
 				r.Dad = p
@@ -169,7 +203,9 @@ func release(p *Node) *Node {
 			p.Dad = null
 			break
 		} else {
+			this.push(R)
 			l := R.Lsn
+			this.rot.single++
 			p.Rsn, p.Dad, R.Lsn, R.Dad = l, R, p, D
 			if l != null {
 				l.Dad = p
@@ -182,6 +218,10 @@ func release(p *Node) *Node {
 			D, R = R, l
 		}
 	}
+	o := D // 被删除节点最后的父节点
+	if o == q {
+		o = q.Dad
+	}
 	D, p, q = q.Dad, q.Lsn, q.Rsn
 	if D != null {
 		if D.Lsn == q {
@@ -190,70 +230,89 @@ func release(p *Node) *Node {
 			D.Rsn = p
 		}
 	}
-	p.Dad = D
+	if p != null {
+		p.Dad = D
+	}
+	this.repair(o)
 	return p
 }
 
-// 插入键值对，如果键已存在，则更新值。w为优先级；n、s构成键；v为值。
-func (this *Treap) Update(w int64, n typeA, s typeB, v typeC) {
-	var p, q *Node
-	k := Key{n, s}
+// 查找键对应的节点，沿途下传暂存的更新。找到时p为该节点；否则p为null，
+// q为新节点应有的父节点（树堆为空时为null），sp表示新节点是否应作为q的右子节点
+func (this *Treap) locate(k *Key) (p, q *Node, sp bool) {
 	for q, p = null, this.root; p != null; {
-		switch compare(&p.Key, &k) {
+		this.push(p)
+		switch compare(&p.item.Key, k) {
 		case -1:
-			q, p = p, p.Rsn
+			q, p, sp = p, p.Rsn, true
 		case +1:
-			q, p = p, p.Lsn
+			q, p, sp = p, p.Lsn, false
 		default:
-			p.Val = v
-			return
+			return p, q, sp
 		}
 	}
+	return p, q, sp
+}
+
+// 将新的键值对作为q的子节点（sp为true时为右子节点）加入并维护树堆，返回新节点
+func (this *Treap) attach(q *Node, sp bool, w int64, k *Key, v typeC) (p *Node) {
 	p = new(Node)
-	*p = Node{w, item{k, v}, treePointer{null, null, null}}
+	*p = Node{w, item{*k, v}, treePointer{null, null, null}, augment{}}
+	this.mod++
 	if q == null {
+		this.pull(p)
 		this.root = p
-		return
+		return p
 	}
-	switch compare(&q.Key, &k) {
-	case -1:
+	if sp {
 		q.Rsn = p
-	case +1:
+	} else {
 		q.Lsn = p
 	}
 	p.Dad = q
-	this.root = arrange(p)
+	this.root = this.arrange(p)
+	return p
+}
+
+// 插入键值对，如果键已存在，则更新值。w为优先级；n、s构成键；v为值。
+func (this *Treap) Update(w int64, n typeA, s typeB, v typeC) {
+	if debug {
+		defer this.check()
+	}
+	k := &Key{n, s}
+	p, q, sp := this.locate(k)
+	if p != null {
+		p.item.Val = v
+		this.repair(p)
+		return
+	}
+	this.attach(q, sp, w, k, v)
 }
 
 // 插入键值对，不管键存不存在，都插入新的键值对。w为优先级；n、s构成键；v为值。
 func (this *Treap) Insert(w int64, n typeA, s typeB, v typeC) {
-	var p, q *Node
+	if debug {
+		defer this.check()
+	}
+	var (
+		p, q *Node
+		sp   bool // 新节点是否作为q的右子节点
+	)
 	k := Key{n, s}
 	for q, p = null, this.root; p != null; {
-		switch compare(&p.Key, &k) {
+		this.push(p)
+		switch compare(&p.item.Key, &k) {
 		case -1:
-			q, p = p, p.Rsn
+			q, p, sp = p, p.Rsn, true
 		case +1:
-			q, p = p, p.Lsn
+			q, p, sp = p, p.Lsn, false
 		default:
-			for q, p = p, p.Rsn; p != null; q, p = p, p.Lsn {
+			for q, p, sp = p, p.Rsn, true; p != null; q, p, sp = p, p.Lsn, false {
+				this.push(p)
 			}
 		}
 	}
-	p = new(Node)
-	*p = Node{w, item{k, v}, treePointer{null, null, null}}
-	if q == null {
-		this.root = p
-		return
-	}
-	switch compare(&q.Key, &k) {
-	case -1:
-		q.Rsn = p
-	case +1:
-		q.Lsn = p
-	}
-	p.Dad = q
-	this.root = arrange(p)
+	this.attach(q, sp, w, &k, v)
 }
 
 // 使用树堆为底层结构的优先级队列
@@ -265,18 +324,22 @@ func NewPQ() *PQ {
 
 // 添加任务或者更新同一优先级的任务
 func (this *PQ) Update(w int64, v typeC) {
-	this.Treap.Update(w, rd.Int63(), "", v)
+	this.Treap.Update(w, this.rnd.Int63(), "", v)
 }
 
 // 不管是否存在同一优先级的任务都添加任务
 func (this *PQ) Insert(w int64, v typeC) {
-	this.Treap.Insert(w, rd.Int63(), "", v)
+	this.Treap.Insert(w, this.rnd.Int63(), "", v)
 }
 
 // 释放最高优先级的任务
 func (this *PQ) Pop() *Node {
+	if debug {
+		defer this.check()
+	}
 	if p := this.root; p != null {
-		this.root = release(p)
+		this.mod++
+		this.root = this.release(p)
 		return p
 	}
 	return nil
@@ -290,6 +353,32 @@ func (this *PQ) Peek() *Node {
 	return nil
 }
 
+// 添加任务并返回其节点，该节点之后可以交给Remove删除
+func (this *PQ) Push(w int64, v typeC) *Node {
+	if debug {
+		defer this.check()
+	}
+	for {
+		k := &Key{this.rnd.Int63(), ""}
+		if p, q, sp := this.locate(k); p == null {
+			return this.attach(q, sp, w, k, v)
+		}
+	}
+}
+
+// 删除队列中的任务，p须是本队列中尚未被删除的节点
+func (this *PQ) Remove(p *Node) {
+	if debug {
+		defer this.check()
+	}
+	this.expose(p)
+	d := p.Dad
+	if r := this.release(p); d == null {
+		this.root = r
+	}
+	this.mod++
+}
+
 // 创建一个以树堆为底层结构的二叉搜索树
 func NewBST() *BST {
 	p := new(BST)
@@ -299,12 +388,12 @@ func NewBST() *BST {
 
 // 添加键值对或者更新已存在的键对应的值
 func (this *BST) Update(n typeA, s typeB, v typeC) {
-	this.Treap.Update(rd.Int63(), n, s, v)
+	this.Treap.Update(this.rnd.Int63(), n, s, v)
 }
 
 // 添加键值对，即使键已存在仍然添加
 func (this *BST) Insert(n typeA, s typeB, v typeC) {
-	this.Treap.Insert(rd.Int63(), n, s, v)
+	this.Treap.Insert(this.rnd.Int63(), n, s, v)
 }
 
 // 根据键查找值
@@ -312,9 +401,10 @@ func (this *BST) Search(n typeA, s typeB) typeC {
 	p := this.root
 	k := Key{n, s}
 	for p != null {
-		switch compare(&p.Key, &k) {
+		this.push(p)
+		switch compare(&p.item.Key, &k) {
 		case 0:
-			return p.Val
+			return p.item.Val
 		case 1:
 			p = p.Lsn
 		default:
@@ -324,22 +414,19 @@ func (this *BST) Search(n typeA, s typeB) typeC {
 	return nil
 }
 
-// 删除键值对
-func (this *BST) Delete(n typeA, s typeB) {
-	var p, q *Node
-	k := Key{n, s}
-	for q, p = null, this.root; p != null; {
-		switch compare(&p.Key, &k) {
-		case -1:
-			q, p = p, p.Rsn
-		case +1:
-			q, p = p, p.Lsn
-		default:
-			p = release(p)
-			if q == null {
-				this.root = p
-			}
-			return
-		}
+// 删除键值对，返回被删除的值以及键是否存在
+func (this *BST) Delete(n typeA, s typeB) (typeC, bool) {
+	if debug {
+		defer this.check()
+	}
+	p, q, _ := this.locate(&Key{n, s})
+	if p == null {
+		return nil, false
+	}
+	v := p.item.Val
+	this.mod++
+	if p = this.release(p); q == null {
+		this.root = p
 	}
+	return v, true
 }
@@ -0,0 +1,79 @@
+package treap
+
+// 调用回调函数f，f执行期间若树堆的节点被插入或删除过则panic
+func (this *Treap) call(f func()) {
+	m := this.mod
+	f()
+	if this.mod != m {
+		panic(ErrModified)
+	}
+}
+
+// 插入或更新键值对，返回原有的值以及键是否已存在
+func (this *BST) Put(n typeA, s typeB, v typeC) (old typeC, replaced bool) {
+	if debug {
+		defer this.check()
+	}
+	k := &Key{n, s}
+	p, q, sp := this.locate(k)
+	if p != null {
+		old, p.item.Val = p.item.Val, v
+		this.repair(p)
+		return old, true
+	}
+	this.attach(q, sp, this.rnd.Int63(), k, v)
+	return nil, false
+}
+
+// 键已存在时返回其值，loaded为true；否则插入f返回的值并返回之，loaded为false。
+// f只在键不存在时被调用一次，其间修改本树会引发panic
+func (this *BST) GetOrInsert(n typeA, s typeB, f func() typeC) (v typeC, loaded bool) {
+	if debug {
+		defer this.check()
+	}
+	k := &Key{n, s}
+	p, q, sp := this.locate(k)
+	if p != null {
+		return p.item.Val, true
+	}
+	this.call(func() { v = f() })
+	this.attach(q, sp, this.rnd.Int63(), k, v)
+	return v, false
+}
+
+// 以键原有的值（键不存在时old为nil、ok为false）调用f，keep为true时将键的值设为f返回的值，
+// 为false时删除该键（键不存在时什么也不做）。只查找一次，返回键最终的值以及键是否存在。
+// f执行期间修改本树会引发panic
+func (this *BST) Compute(n typeA, s typeB, f func(old typeC, ok bool) (v typeC, keep bool)) (typeC, bool) {
+	if debug {
+		defer this.check()
+	}
+	var (
+		v    typeC
+		keep bool
+		old  typeC
+	)
+	k := &Key{n, s}
+	p, q, sp := this.locate(k)
+	ok := p != null
+	if ok {
+		old = p.item.Val
+	}
+	this.call(func() { v, keep = f(old, ok) })
+	switch {
+	case !keep:
+		if ok {
+			this.mod++
+			if p = this.release(p); q == null {
+				this.root = p
+			}
+		}
+		return nil, false
+	case ok:
+		p.item.Val = v
+		this.repair(p)
+	default:
+		this.attach(q, sp, this.rnd.Int63(), k, v)
+	}
+	return v, true
+}
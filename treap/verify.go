@@ -0,0 +1,62 @@
+package treap
+
+import "fmt"
+
+// 检查以p为根的子树并按中序将节点追加到v中，o为p应有的父节点，m记录已访问的节点以发现环
+func verify(p, o *Node, m map[*Node]bool, v *[]*Node) error {
+	if p == null {
+		return nil
+	}
+	if m[p] {
+		return fmt.Errorf("treap: 节点(%d, %s)被重复访问，树中存在环", p.item.Key.N, p.item.Key.S)
+	}
+	m[p] = true
+	if p.Dad != o {
+		return fmt.Errorf("treap: 节点(%d, %s)的父节点指针错误", p.item.Key.N, p.item.Key.S)
+	}
+	if p.Lsn == nil || p.Rsn == nil {
+		return fmt.Errorf("treap: 节点(%d, %s)的子节点指针为nil", p.item.Key.N, p.item.Key.S)
+	}
+	for _, c := range [2]*Node{p.Lsn, p.Rsn} {
+		if c != null && c.wgt < p.wgt {
+			return fmt.Errorf("treap: 节点(%d, %s)的优先级%d小于其父节点的优先级%d", c.item.Key.N, c.item.Key.S, c.wgt, p.wgt)
+		}
+	}
+	if err := verify(p.Lsn, p, m, v); err != nil {
+		return err
+	}
+	*v = append(*v, p)
+	return verify(p.Rsn, p, m, v)
+}
+
+// 检查树堆的各项不变式：优先级的堆序、父节点指针、键的顺序以及懒惰标记树堆中的子树大小。
+// 全部满足时返回nil，否则返回描述第一处违反的错误。
+func (this *Treap) Verify() error {
+	var v []*Node
+	if null.wgt != int64(^uint64(0)>>1) || null.Lsn != nil || null.Rsn != nil {
+		return fmt.Errorf("treap: 空节点被修改")
+	}
+	if err := verify(this.root, null, make(map[*Node]bool), &v); err != nil {
+		return err
+	}
+	if this.aux != nil {
+		for _, p := range v {
+			if n := p.Lsn.cnt + p.Rsn.cnt + 1; p.cnt != n {
+				return fmt.Errorf("treap: 节点(%d, %s)记录的子树大小为%d，实际为%d", p.item.Key.N, p.item.Key.S, p.cnt, n)
+			}
+		}
+	}
+	for i := 1; i < len(v); i++ {
+		if compare(&v[i-1].item.Key, &v[i].item.Key) > 0 {
+			return fmt.Errorf("treap: 第%d个节点(%d, %s)的键小于其前驱", i, v[i].item.Key.N, v[i].item.Key.S)
+		}
+	}
+	return nil
+}
+
+// 调试模式下，每次修改后检查不变式，不满足时立即panic
+func (this *Treap) check() {
+	if err := this.Verify(); err != nil {
+		panic(err)
+	}
+}
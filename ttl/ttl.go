@@ -0,0 +1,226 @@
+// ttl包提供键值对会自动过期的映射：以avl.AVL按键查找，以treap.PQ按过期时间排列，
+// 过期的键值对可以由ExpireNow主动清除，也可以交给后台的清扫协程定期清除
+package ttl
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hydra13142/container/avl"
+	"github.com/hydra13142/container/treap"
+)
+
+type typeA = int64
+
+type typeB = string
+
+type typeC = interface{}
+
+// 映射的键
+type Key = avl.Key
+
+// 过期时被清除的键值对
+type Entry struct {
+	Key      Key
+	Val      typeC
+	Deadline time.Time
+}
+
+// 映射中保存的值，node为其在优先级队列中的节点，永不过期时为nil
+type record struct {
+	key  Key
+	val  typeC
+	ttl  time.Duration
+	node *treap.Node
+}
+
+// 键值对会自动过期的映射，可以被多个协程同时使用
+type Map struct {
+	mu   sync.Mutex
+	tree *avl.AVL  // 键到*record
+	pq   *treap.PQ // 以过期时间（UnixNano）为优先级的*record
+	now  func() time.Time
+	size int
+	stop chan struct{}
+	done chan struct{}
+}
+
+// 创建一个映射，now为获取当前时间的函数，为nil时使用time.Now；测试时可以传入可控的时钟
+func New(now func() time.Time) *Map {
+	if now == nil {
+		now = time.Now
+	}
+	return &Map{tree: avl.New(), pq: treap.NewPQ(), now: now}
+}
+
+// 返回now之后ttl的时刻（UnixNano），超出int64的范围时取math.MaxInt64，即实际上永不过期
+func deadline(now time.Time, ttl time.Duration) int64 {
+	t := now.UnixNano()
+	if t > math.MaxInt64-int64(ttl) {
+		return math.MaxInt64
+	}
+	return t + int64(ttl)
+}
+
+// 按记录的ttl重新安排过期时间，ttl不大于0时永不过期
+func (this *Map) schedule(r *record, now time.Time) {
+	if r.node != nil {
+		this.pq.Remove(r.node)
+		r.node = nil
+	}
+	if r.ttl > 0 {
+		r.node = this.pq.Push(deadline(now, r.ttl), r)
+	}
+}
+
+// 记录是否在now时已经过期
+func expired(r *record, now time.Time) bool {
+	return r.node != nil && r.node.Weight() <= now.UnixNano()
+}
+
+// 移除记录
+func (this *Map) remove(r *record) {
+	if r.node != nil {
+		this.pq.Remove(r.node)
+	}
+	this.tree.Delete(r.key.N, r.key.S)
+	this.size--
+}
+
+// 查找未过期的记录，已过期而尚未清除的记录会被顺便移除
+func (this *Map) lookup(n typeA, s typeB) *record {
+	p := this.tree.Search(n, s)
+	if p == nil {
+		return nil
+	}
+	r := p.Val().(*record)
+	if expired(r, this.now()) {
+		this.remove(r)
+		return nil
+	}
+	return r
+}
+
+// 设置键值对，ttl之后过期；ttl不大于0时永不过期，过期时间超出UnixNano能表示的范围时取其上限。键已存在时值和过期时间都被替换
+func (this *Map) Set(n typeA, s typeB, v typeC, ttl time.Duration) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var r *record
+	if p := this.tree.Search(n, s); p != nil {
+		r = p.Val().(*record)
+	} else {
+		r = &record{key: Key{N: n, S: s}}
+		this.tree.Update(n, s, r)
+		this.size++
+	}
+	r.val, r.ttl = v, ttl
+	this.schedule(r, this.now())
+}
+
+// 返回键对应的值，键不存在或已过期时ok为false
+func (this *Map) Get(n typeA, s typeB) (v typeC, ok bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if r := this.lookup(n, s); r != nil {
+		return r.val, true
+	}
+	return nil, false
+}
+
+// 将未过期的键的过期时间重新设为当前时间加上其ttl，返回键是否存在且未过期
+func (this *Map) Touch(n typeA, s typeB) bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	r := this.lookup(n, s)
+	if r == nil {
+		return false
+	}
+	this.schedule(r, this.now())
+	return true
+}
+
+// 返回未过期的键的过期时间，永不过期时返回零值；键不存在或已过期时ok为false
+func (this *Map) Deadline(n typeA, s typeB) (t time.Time, ok bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	r := this.lookup(n, s)
+	if r == nil {
+		return time.Time{}, false
+	}
+	if r.node != nil {
+		t = time.Unix(0, r.node.Weight())
+	}
+	return t, true
+}
+
+// 删除键值对，返回被删除的值以及键是否存在且未过期
+func (this *Map) Delete(n typeA, s typeB) (typeC, bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	r := this.lookup(n, s)
+	if r == nil {
+		return nil, false
+	}
+	this.remove(r)
+	return r.val, true
+}
+
+// 返回键值对的数目，其中可能包括已过期而尚未清除的键值对
+func (this *Map) Len() int {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.size
+}
+
+// 清除过期时间不晚于now的所有键值对，按过期时间的顺序返回之。耗时O(m log n)，m为清除的数目
+func (this *Map) ExpireNow(now time.Time) []Entry {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var v []Entry
+	for p := this.pq.Peek(); p != nil && p.Weight() <= now.UnixNano(); p = this.pq.Peek() {
+		r := p.Val().(*record)
+		v = append(v, Entry{r.key, r.val, time.Unix(0, p.Weight())})
+		this.remove(r)
+	}
+	return v
+}
+
+// 启动后台的清扫协程，每隔interval以当前时间调用一次ExpireNow，清除了键值对时将其交给f（f可以为nil）。
+// interval不为正数或清扫协程已在运行时什么也不做；f在清扫协程中执行，可以调用本映射除StopSweeper以外的方法
+func (this *Map) StartSweeper(interval time.Duration, f func([]Entry)) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if interval <= 0 || this.stop != nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	this.stop, this.done = stop, done
+	go func() {
+		defer close(done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				if v := this.ExpireNow(this.now()); len(v) > 0 && f != nil {
+					f(v)
+				}
+			}
+		}
+	}()
+}
+
+// 停止后台的清扫协程并等待其退出，清扫协程没有运行时什么也不做
+func (this *Map) StopSweeper() {
+	this.mu.Lock()
+	stop, done := this.stop, this.done
+	this.stop, this.done = nil, nil
+	this.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,198 @@
+package ttl
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 可控的时钟，只在测试调用add时前进
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (this *clock) now() time.Time {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.t
+}
+
+func (this *clock) add(d time.Duration) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.t = this.t.Add(d)
+}
+
+func TestExpire(t *testing.T) {
+	c := &clock{t: time.Unix(1000, 0)}
+	m := New(c.now)
+	m.Set(1, "", "a", 10*time.Second)
+	m.Set(2, "", "b", 20*time.Second)
+	m.Set(3, "", "c", 0)
+
+	if d, ok := m.Deadline(1, ""); !ok || !d.Equal(time.Unix(1010, 0)) {
+		t.Fatalf("Deadline(1) = %v, %v", d, ok)
+	}
+	if d, ok := m.Deadline(3, ""); !ok || !d.IsZero() {
+		t.Fatalf("Deadline(3) = %v, %v", d, ok)
+	}
+
+	c.add(5 * time.Second)
+	if !m.Touch(1, "") {
+		t.Fatal("Touch(1) = false")
+	}
+	if d, _ := m.Deadline(1, ""); !d.Equal(time.Unix(1015, 0)) {
+		t.Fatalf("Deadline(1) after Touch = %v", d)
+	}
+
+	c.add(10 * time.Second)
+	if _, ok := m.Get(1, ""); ok {
+		t.Fatal("Get(1) after expiry: ok = true")
+	}
+	if m.Touch(1, "") {
+		t.Fatal("Touch(1) after expiry = true")
+	}
+	if v, ok := m.Get(2, ""); !ok || v != "b" {
+		t.Fatalf("Get(2) = %v, %v", v, ok)
+	}
+
+	c.add(5 * time.Second)
+	v := m.ExpireNow(c.now())
+	if len(v) != 1 || v[0].Key.N != 2 || v[0].Val != "b" || !v[0].Deadline.Equal(time.Unix(1020, 0)) {
+		t.Fatalf("ExpireNow = %v", v)
+	}
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d", m.Len())
+	}
+
+	m.Set(3, "", "d", time.Second)
+	c.add(time.Second)
+	if v := m.ExpireNow(c.now()); len(v) != 1 || v[0].Val != "d" {
+		t.Fatalf("ExpireNow after resetting ttl = %v", v)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len = %d", m.Len())
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	c := &clock{t: time.Unix(1000, 0)}
+	m := New(c.now)
+	m.Set(1, "", "a", time.Duration(math.MaxInt64))
+	if v, ok := m.Get(1, ""); !ok || v != "a" {
+		t.Fatalf("Get = %v, %v", v, ok)
+	}
+	if d, ok := m.Deadline(1, ""); !ok || d.UnixNano() != math.MaxInt64 {
+		t.Fatalf("Deadline = %v, %v", d, ok)
+	}
+	c.add(100 * 365 * 24 * time.Hour)
+	if v := m.ExpireNow(c.now()); len(v) != 0 {
+		t.Fatalf("ExpireNow = %v", v)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	type state struct {
+		val      int
+		deadline time.Time // 零值表示永不过期
+	}
+	rd := rand.New(rand.NewSource(1))
+	c := &clock{t: time.Unix(1000, 0)}
+	m := New(c.now)
+	ref := map[int64]state{}
+	for i := 0; i < 5000; i++ {
+		k := int64(rd.Intn(100))
+		switch rd.Intn(6) {
+		case 0, 1:
+			d := time.Duration(rd.Intn(50)) * time.Second
+			m.Set(k, "", i, d)
+			s := state{val: i}
+			if d > 0 {
+				s.deadline = c.now().Add(d)
+			}
+			ref[k] = s
+		case 2:
+			v, ok := m.Get(k, "")
+			s, has := ref[k]
+			if ok != has || ok && v != s.val {
+				t.Fatalf("Get(%d) = %v, %v; want %v, %v", k, v, ok, s.val, has)
+			}
+		case 3:
+			_, ok := m.Delete(k, "")
+			if _, has := ref[k]; ok != has {
+				t.Fatalf("Delete(%d) = %v; want %v", k, ok, has)
+			}
+			delete(ref, k)
+		case 4:
+			c.add(time.Duration(rd.Intn(5)) * time.Second)
+			now := c.now()
+			v := m.ExpireNow(now)
+			n := 0
+			for k, s := range ref {
+				if !s.deadline.IsZero() && !s.deadline.After(now) {
+					delete(ref, k)
+					n++
+				}
+			}
+			if len(v) != n {
+				t.Fatalf("ExpireNow removed %d entries; want %d", len(v), n)
+			}
+			for j := 1; j < len(v); j++ {
+				if v[j].Deadline.Before(v[j-1].Deadline) {
+					t.Fatal("ExpireNow: entries out of deadline order")
+				}
+			}
+			if m.Len() != len(ref) {
+				t.Fatalf("Len = %d; want %d", m.Len(), len(ref))
+			}
+		case 5:
+			ok := m.Touch(k, "")
+			s, has := ref[k]
+			if ok != has {
+				t.Fatalf("Touch(%d) = %v; want %v", k, ok, has)
+			}
+			if ok && !s.deadline.IsZero() {
+				d, _ := m.Deadline(k, "")
+				if d.Before(s.deadline) {
+					t.Fatalf("Deadline(%d) moved back after Touch", k)
+				}
+				s.deadline = d
+				ref[k] = s
+			}
+		}
+		if err := m.pq.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSweeper(t *testing.T) {
+	c := &clock{t: time.Unix(1000, 0)}
+	m := New(c.now)
+	m.Set(1, "", "a", time.Second)
+	m.Set(2, "", "b", 0)
+	m.StartSweeper(0, nil)
+	m.StartSweeper(-time.Second, nil)
+	if m.stop != nil {
+		t.Fatal("StartSweeper started with a non-positive interval")
+	}
+	ch := make(chan []Entry, 1)
+	m.StartSweeper(time.Millisecond, func(v []Entry) { ch <- v })
+	c.add(2 * time.Second)
+	select {
+	case v := <-ch:
+		if len(v) != 1 || v[0].Key.N != 1 {
+			t.Fatalf("swept %v", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("sweeper did not run")
+	}
+	m.StopSweeper()
+	m.StopSweeper()
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d", m.Len())
+	}
+}
@@ -0,0 +1,345 @@
+// container包提供各容器共用的辅助类型，容器本身位于avl、sbt、treap、skiplist等子包中
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 元组中各种元素编码时的类型标记，不同类型的元素按标记的顺序比较
+const (
+	tagInt    = 0x10
+	tagUint   = 0x11 // 超出int64范围的无符号整数，排在所有tagInt之后
+	tagFloat  = 0x20
+	tagString = 0x30
+	tagBytes  = 0x31
+	tagTime   = 0x40
+)
+
+// 由任意多个元素构成的复合键，按元素逐个进行字典序比较，较短的前缀较小。
+// 元素可以是各种有符号、无符号整数，float32/float64、string、[]byte和time.Time，
+// 整数按数值比较，浮点数视为float64，时间只保留秒和纳秒而不保留时区。
+// 编码后的字节串可以直接用bytes.Compare比较，其顺序与Compare相同，并且没有一个元组的编码
+// 是另一个不以其为前缀的元组的编码的前缀，因此可以按编码前缀查找以某个元组开头的全部元组。
+//
+// 各容器的Key仍是固定的(N, S)，不直接接受元组；以元组为键的约定用法是把Encode的结果作为Key的S部分、
+// N取0，这样容器中键的顺序即是元组的顺序。table、index包均按这一约定使用元组。
+type Tuple []interface{}
+
+// 返回无符号整数元素的值，x不是无符号整数时ok为false
+func unsigned(x interface{}) (u uint64, ok bool) {
+	switch v := x.(type) {
+	case uint:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case uintptr:
+		return uint64(v), true
+	}
+	return 0, false
+}
+
+// 返回元素的类型标记，不支持的类型会引发panic
+func tag(x interface{}) byte {
+	if u, ok := unsigned(x); ok {
+		if u > math.MaxInt64 {
+			return tagUint
+		}
+		return tagInt
+	}
+	switch x.(type) {
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return tagInt
+	case float32, float64:
+		return tagFloat
+	case string:
+		return tagString
+	case []byte:
+		return tagBytes
+	case time.Time:
+		return tagTime
+	}
+	panic(fmt.Sprintf("container: 元组不支持%T类型的元素", x))
+}
+
+// 将整数元素转换为int64，无符号整数须不超出int64的范围
+func integer(x interface{}) int64 {
+	if u, ok := unsigned(x); ok {
+		return int64(u)
+	}
+	switch v := x.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	default:
+		return int64(x.(uint32))
+	}
+}
+
+// 将浮点数转换为保序的无符号整数：负数按位取反，非负数置符号位
+func ordered(x interface{}) uint64 {
+	var f float64
+	if v, ok := x.(float32); ok {
+		f = float64(v)
+	} else {
+		f = x.(float64)
+	}
+	b := math.Float64bits(f)
+	if b>>63 != 0 {
+		return ^b
+	}
+	return b | 1<<63
+}
+
+// 比较两个元素，类型不同时按类型标记比较
+func compare(x, y interface{}) int {
+	a, b := tag(x), tag(y)
+	if a != b {
+		if a < b {
+			return -1
+		}
+		return +1
+	}
+	switch a {
+	case tagInt:
+		u, v := integer(x), integer(y)
+		switch {
+		case u < v:
+			return -1
+		case u > v:
+			return +1
+		}
+	case tagUint:
+		u, _ := unsigned(x)
+		v, _ := unsigned(y)
+		switch {
+		case u < v:
+			return -1
+		case u > v:
+			return +1
+		}
+	case tagFloat:
+		u, v := ordered(x), ordered(y)
+		switch {
+		case u < v:
+			return -1
+		case u > v:
+			return +1
+		}
+	case tagString:
+		u, v := x.(string), y.(string)
+		switch {
+		case u < v:
+			return -1
+		case u > v:
+			return +1
+		}
+	case tagBytes:
+		return bytes.Compare(x.([]byte), y.([]byte))
+	case tagTime:
+		u, v := x.(time.Time), y.(time.Time)
+		switch {
+		case u.Before(v):
+			return -1
+		case u.After(v):
+			return +1
+		}
+	}
+	return 0
+}
+
+// 按字典序比较两个元组，返回-1、0或+1；含有不支持的元素时panic
+func (this Tuple) Compare(other Tuple) int {
+	for i := 0; i < len(this) && i < len(other); i++ {
+		if c := compare(this[i], other[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(this) < len(other):
+		return -1
+	case len(this) > len(other):
+		return +1
+	}
+	return 0
+}
+
+// 追加整数按符号位翻转后的大端序编码，使有符号整数的顺序与字节串的顺序一致
+func AppendInt(dst []byte, n int64) []byte {
+	return binary.BigEndian.AppendUint64(dst, uint64(n)^1<<63)
+}
+
+// 追加字节串的转义编码：0x00编码为0x00 0xff，末尾追加0x00 0x01作为结束标记，
+// 这样编码既能自我定界，又保持了字节串之间的顺序
+func AppendString(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 {
+			dst = append(dst, 0, 0xff)
+		} else {
+			dst = append(dst, s[i])
+		}
+	}
+	return append(dst, 0, 1)
+}
+
+// 读取AppendInt编码的整数，返回整数和剩余的字节
+func ReadInt(b []byte) (int64, []byte, error) {
+	if len(b) < 8 {
+		return 0, nil, fmt.Errorf("container: 整数编码的长度不足8字节")
+	}
+	return int64(binary.BigEndian.Uint64(b) ^ 1<<63), b[8:], nil
+}
+
+// 读取AppendString编码的字节串，返回字节串和剩余的字节
+func ReadString(b []byte) ([]byte, []byte, error) {
+	var s []byte
+	for i := 0; i+1 < len(b); i++ {
+		if b[i] != 0 {
+			s = append(s, b[i])
+			continue
+		}
+		switch b[i+1] {
+		case 1:
+			return s, b[i+2:], nil
+		case 0xff:
+			s = append(s, 0)
+			i++
+		default:
+			return nil, nil, fmt.Errorf("container: 字节串编码中存在无效的转义0x00 0x%02x", b[i+1])
+		}
+	}
+	return nil, nil, fmt.Errorf("container: 字节串编码缺少结束标记")
+}
+
+// 追加由整数n和字符串s构成的键的保序编码：AppendInt编码的n之后接AppendString编码的s。
+// 各容器包中Key.AppendEncoded均使用这一编码，两个键编码后的字节顺序与先比较n、再比较s的顺序一致
+func AppendKey(dst []byte, n int64, s string) []byte {
+	return AppendString(AppendInt(dst, n), s)
+}
+
+// 读取AppendKey编码的键，编码无效或之后有多余的字节时返回错误
+func ReadKey(b []byte) (int64, string, error) {
+	n, b, err := ReadInt(b)
+	if err != nil {
+		return 0, "", err
+	}
+	s, b, err := ReadString(b)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(b) != 0 {
+		return 0, "", fmt.Errorf("container: 键的编码之后有%d个多余的字节", len(b))
+	}
+	return n, string(s), nil
+}
+
+// 将元组的保序编码追加到dst中并返回，含有不支持的元素时panic
+func (this Tuple) AppendEncoded(dst []byte) []byte {
+	for _, x := range this {
+		t := tag(x)
+		dst = append(dst, t)
+		switch t {
+		case tagInt:
+			dst = AppendInt(dst, integer(x))
+		case tagUint:
+			u, _ := unsigned(x)
+			dst = binary.BigEndian.AppendUint64(dst, u)
+		case tagFloat:
+			dst = binary.BigEndian.AppendUint64(dst, ordered(x))
+		case tagString:
+			dst = AppendString(dst, x.(string))
+		case tagBytes:
+			dst = AppendString(dst, string(x.([]byte)))
+		case tagTime:
+			v := x.(time.Time)
+			dst = AppendInt(dst, v.Unix())
+			dst = binary.BigEndian.AppendUint32(dst, uint32(v.Nanosecond()))
+		}
+	}
+	return dst
+}
+
+// 返回元组的保序编码，可直接用作容器Key的S部分
+func (this Tuple) Encode() string {
+	return string(this.AppendEncoded(nil))
+}
+
+// 由保序编码还原元组：整数还原为int64，超出int64范围的无符号整数还原为uint64，
+// 浮点数还原为float64，时间还原为UTC时间
+func DecodeTuple(b []byte) (Tuple, error) {
+	var (
+		t   Tuple
+		err error
+	)
+	for len(b) > 0 {
+		c := b[0]
+		b = b[1:]
+		switch c {
+		case tagInt:
+			var n int64
+			if n, b, err = ReadInt(b); err != nil {
+				return nil, err
+			}
+			t = append(t, n)
+		case tagUint:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("container: 无符号整数编码的长度不足8字节")
+			}
+			u := binary.BigEndian.Uint64(b)
+			if u <= math.MaxInt64 {
+				return nil, fmt.Errorf("container: 无符号整数%d应当按有符号整数编码", u)
+			}
+			t, b = append(t, u), b[8:]
+		case tagFloat:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("container: 浮点数编码的长度不足8字节")
+			}
+			u := binary.BigEndian.Uint64(b)
+			if u>>63 != 0 {
+				u &^= 1 << 63
+			} else {
+				u = ^u
+			}
+			t, b = append(t, math.Float64frombits(u)), b[8:]
+		case tagString, tagBytes:
+			var s []byte
+			if s, b, err = ReadString(b); err != nil {
+				return nil, err
+			}
+			if c == tagString {
+				t = append(t, string(s))
+			} else {
+				if s == nil {
+					s = []byte{}
+				}
+				t = append(t, s)
+			}
+		case tagTime:
+			var n int64
+			if n, b, err = ReadInt(b); err != nil {
+				return nil, err
+			}
+			if len(b) < 4 {
+				return nil, fmt.Errorf("container: 时间编码的长度不足12字节")
+			}
+			t, b = append(t, time.Unix(n, int64(binary.BigEndian.Uint32(b))).UTC()), b[4:]
+		default:
+			return nil, fmt.Errorf("container: 元组编码中存在无效的类型标记0x%02x", c)
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,150 @@
+package container
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 随机生成一个元素，取值集中在容易出错的边界附近，以便产生大量相等和互为前缀的元组
+func element(rd *rand.Rand) interface{} {
+	switch rd.Intn(8) {
+	case 0:
+		return rd.Int63n(7) - 3
+	case 1:
+		return []int64{math.MinInt64, math.MaxInt64, -1, 0}[rd.Intn(4)]
+	case 2:
+		return []uint64{0, 1, math.MaxInt64, math.MaxInt64 + 1, math.MaxUint64}[rd.Intn(5)]
+	case 3:
+		return []float64{-1.5, 0, 2, math.Inf(1), math.Inf(-1), -1e300}[rd.Intn(6)]
+	case 4:
+		return []string{"", "a", "a\x00", "a\x00b", "\x00", "\x00\xff", "b", "a\x01"}[rd.Intn(8)]
+	case 5:
+		return []byte([]string{"", "x", "x\x00", "\x00\x01"}[rd.Intn(4)])
+	case 6:
+		return []interface{}{int8(-3), uint8(200), int32(-1), uint32(7), uint(3)}[rd.Intn(5)]
+	}
+	return time.Unix(rd.Int63n(5)-2, rd.Int63n(3)).UTC()
+}
+
+func tuple(rd *rand.Rand) Tuple {
+	t := Tuple{}
+	for i := rd.Intn(4); i > 0; i-- {
+		t = append(t, element(rd))
+	}
+	return t
+}
+
+// 解码后的元组：整数还原为int64或uint64，浮点数还原为float64
+func normal(t Tuple) Tuple {
+	v := make(Tuple, len(t))
+	for i, x := range t {
+		switch tag(x) {
+		case tagInt:
+			v[i] = integer(x)
+		case tagUint:
+			v[i], _ = unsigned(x)
+		default:
+			if f, ok := x.(float32); ok {
+				x = float64(f)
+			}
+			v[i] = x
+		}
+	}
+	return v
+}
+
+func TestTupleRoundTrip(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		a := tuple(rd)
+		b, err := DecodeTuple([]byte(a.Encode()))
+		if err != nil {
+			t.Fatalf("DecodeTuple(%v): %v", a, err)
+		}
+		if len(a) == 0 {
+			b = Tuple{}
+		}
+		if want := normal(a); !reflect.DeepEqual(b, want) {
+			t.Fatalf("DecodeTuple(Encode(%v)) = %#v; want %#v", a, b, want)
+		}
+		if b.Compare(a) != 0 {
+			t.Fatalf("decoded tuple %v does not compare equal to %v", b, a)
+		}
+	}
+}
+
+func TestTupleOrder(t *testing.T) {
+	rd := rand.New(rand.NewSource(2))
+	for i := 0; i < 20000; i++ {
+		a, b := tuple(rd), tuple(rd)
+		if rd.Intn(3) == 0 && len(a) > 0 {
+			b = append(append(Tuple{}, a[:rd.Intn(len(a))]...), element(rd))
+		}
+		x, y := a.Encode(), b.Encode()
+		if c, d := a.Compare(b), strings.Compare(x, y); c != d {
+			t.Fatalf("Compare(%v, %v) = %d, but the encodings compare %d", a, b, c, d)
+		}
+		if a.Compare(b) != -b.Compare(a) {
+			t.Fatalf("Compare(%v, %v) is not antisymmetric", a, b)
+		}
+		// 只有b以a为前缀时，b的编码才以a的编码为前缀，table、index借此按前缀查找
+		p := len(a) <= len(b) && a.Compare(b[:len(a)]) == 0
+		if q := strings.HasPrefix(y, x); p != q {
+			t.Fatalf("tuple %v prefix of %v is %v, but encoding prefix is %v", a, b, p, q)
+		}
+	}
+}
+
+func TestTupleUnsigned(t *testing.T) {
+	v := []Tuple{
+		{int64(math.MinInt64)},
+		{-1},
+		{uint(0)},
+		{uint8(1)},
+		{int64(math.MaxInt64)},
+		{uint64(math.MaxInt64 + 1)},
+		{uint64(math.MaxUint64)},
+		{0.0},
+	}
+	for i := 1; i < len(v); i++ {
+		if v[i-1].Compare(v[i]) >= 0 {
+			t.Fatalf("Compare(%v, %v) >= 0", v[i-1], v[i])
+		}
+		if v[i-1].Encode() >= v[i].Encode() {
+			t.Fatalf("encoding of %v is not less than that of %v", v[i-1], v[i])
+		}
+	}
+	if c := (Tuple{uint64(5)}).Compare(Tuple{int8(5)}); c != 0 {
+		t.Fatalf("uint64(5) compares %d with int8(5)", c)
+	}
+	if (Tuple{uint64(5)}).Encode() != (Tuple{int8(5)}).Encode() {
+		t.Fatal("uint64(5) and int8(5) encode differently")
+	}
+}
+
+func TestDecodeTupleInvalid(t *testing.T) {
+	for _, b := range [][]byte{
+		{tagInt, 0, 0},
+		{tagUint, 0, 0, 0, 0, 0, 0, 0, 1},
+		{tagFloat, 1},
+		{tagString, 'a'},
+		{tagString, 'a', 0, 2},
+		{tagTime, 0x80, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0x7f},
+	} {
+		if _, err := DecodeTuple(b); err == nil {
+			t.Errorf("DecodeTuple(%q) succeeded", b)
+		}
+	}
+	if _, _, err := ReadKey(bytes.Repeat([]byte{0}, 7)); err == nil {
+		t.Error("ReadKey accepted a short integer")
+	}
+	if _, _, err := ReadKey(append(AppendKey(nil, 1, "a"), 0)); err == nil {
+		t.Error("ReadKey accepted trailing bytes")
+	}
+}